@@ -0,0 +1,354 @@
+package xreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// BindReport is the result of BindAllowed, with paths as written in the input, such as "address.city".
+type BindReport struct {
+	// Bound are the paths which were written.
+	Bound []string
+	// Blocked are the paths which the role is not allowed to write.
+	Blocked []string
+	// Unknown are the paths which do not match any exported field.
+	Unknown []string
+}
+
+// BindAllowed writes the values of input, typically decoded from client JSON, to the fields of dst, converting
+// them like CallFunc converts arguments, and nested maps are bound to nested structures field by field.
+// The keys can be Go field names or json tag names. The `bind` tag controls which roles can write a field and
+// all its nested fields: `bind:"readonly"` or `bind:"-"` fields are never written, `bind:"admin,owner"` fields
+// can only be written by the listed roles, and fields without the tag can be written by any role. The tags of
+// the embedded structures of promoted fields apply as well, and structures with bind tags nested in slices,
+// arrays or maps are bound element by element, so they must be given as maps too.
+// The writes to forbidden or unknown fields are dropped and listed in the report. If a value cannot be
+// converted, an error is returned and dst is not modified. The dst must be a pointer to a structure.
+func BindAllowed(dst interface{}, input map[string]interface{}, role string) (*BindReport, error) {
+	return bindAllowed(dst, input, role, false)
+}
+
+// BindAllowedStrict has the same functionality as BindAllowed, but if any write is blocked,
+// it returns an error listing the blocked paths and dst is not modified.
+func BindAllowedStrict(dst interface{}, input map[string]interface{}, role string) (*BindReport, error) {
+	return bindAllowed(dst, input, role, true)
+}
+
+// bindWrite is the write of value to the field at the Go path, whose index is resolved like
+// reflect.StructField.Index, but across nested structures and the pointers to them.
+type bindWrite struct {
+	path  string
+	index []int
+	value reflect.Value
+}
+
+type binder struct {
+	role   string
+	dst    reflect.Value
+	report *BindReport
+	writes []bindWrite
+}
+
+func bindAllowed(dst interface{}, input map[string]interface{}, role string, strict bool) (*BindReport, error) {
+	if dst == nil {
+		return nil, errors.New("dst must not be nil")
+	}
+	if reflect.TypeOf(dst).Kind() != reflect.Ptr || Type(dst).Kind() != reflect.Struct {
+		return nil, errors.New("dst must be struct pointer")
+	}
+
+	b := &binder{role: role, dst: Value(dst), report: &BindReport{}}
+	if err := b.plan(Type(dst), input, nil, "", ""); err != nil {
+		return b.report, err
+	}
+	if strict && len(b.report.Blocked) > 0 {
+		return b.report, fmt.Errorf("blocked fields: %s", strings.Join(b.report.Blocked, ", "))
+	}
+
+	return b.report, setFieldsWithUndo(b.dst, b.writes)
+}
+
+// plan checks the input for the struct type typ and collects the writes, sorted by key for a stable report.
+// The index, goPrefix and inPrefix are the index of typ in b.dst and its paths in Go names and in input keys.
+func (b *binder) plan(typ reflect.Type, input map[string]interface{}, index []int, goPrefix, inPrefix string) error {
+	keys := make([]string, 0, len(input))
+	for key := range input {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		inPath := joinPath(inPrefix, key)
+		field, allowed, ok := b.field(typ, key)
+		if !ok {
+			b.report.Unknown = append(b.report.Unknown, inPath)
+			continue
+		}
+		if !allowed {
+			b.report.Blocked = append(b.report.Blocked, inPath)
+			continue
+		}
+
+		fieldIndex := append(append([]int(nil), index...), field.Index...)
+		goPath := joinPath(goPrefix, field.Name)
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if nested, ok := stringMap(input[key]); ok && ft.Kind() == reflect.Struct && ft != timeType {
+			if err := b.plan(ft, nested, fieldIndex, goPath, inPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Reject the fields which cannot be reached, such as through a nil unexported embedded pointer,
+		// before anything is written.
+		if err := checkFieldIndex(b.dst, fieldIndex); err != nil {
+			return fmt.Errorf("field: %s: %w", inPath, err)
+		}
+		v, err := b.bindValue(reflect.ValueOf(input[key]), field.Type, inPath)
+		if err != nil {
+			return err
+		}
+		b.writes = append(b.writes, bindWrite{path: goPath, index: fieldIndex, value: v})
+		b.report.Bound = append(b.report.Bound, inPath)
+	}
+	return nil
+}
+
+// field returns the exported field of the struct type typ for the input key, and whether the role is allowed
+// to write it, which requires the embedded structures of a promoted field to be allowed as well.
+// Fields tagged `json:"-"` are not bound, like with encoding/json.
+func (b *binder) field(typ reflect.Type, key string) (reflect.StructField, bool, bool) {
+	field, ok := fieldByTagOrName(typ, key, "json")
+	if !ok {
+		field, ok = promotedFieldByTag(typ, key, "json")
+	}
+	if !ok || !field.IsExported() || field.Tag.Get("json") == "-" {
+		return field, false, false
+	}
+
+	parent := typ
+	for _, index := range field.Index {
+		f := parent.Field(index)
+		if !b.allowed(f) {
+			return field, false, true
+		}
+		parent = f.Type
+		if parent.Kind() == reflect.Ptr {
+			parent = parent.Elem()
+		}
+	}
+	return field, true, true
+}
+
+// bindValue converts val to typ like convertValue, but structures with bind tags nested in typ, including
+// the elements of slices, arrays and maps, are bound field by field, so that the role is checked for each of
+// their fields. Such structures must be given as maps with string keys. The errors include the input path.
+func (b *binder) bindValue(val reflect.Value, typ reflect.Type, inPath string) (reflect.Value, error) {
+	var empty reflect.Value
+	if val.Kind() == reflect.Interface {
+		val = val.Elem()
+	}
+	if !val.IsValid() || !hasBindTag(typ, make(map[reflect.Type]bool)) {
+		v, err := convertValue(val, typ)
+		if err != nil {
+			return empty, fmt.Errorf("field: %s: %w", inPath, err)
+		}
+		return v, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		if val.Kind() == reflect.Ptr && val.IsNil() {
+			return reflect.Zero(typ), nil
+		}
+		elem, err := b.bindValue(val, typ.Elem(), inPath)
+		if err != nil {
+			return empty, err
+		}
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	case reflect.Struct:
+		input, ok := stringMap(val.Interface())
+		if !ok {
+			return empty, fmt.Errorf("field: %s: cannot use %s as %s, fields must be given as map",
+				inPath, val.Type(), typ)
+		}
+		// The element is reported as bound as a whole, only the dropped fields are reported separately.
+		nested := &binder{role: b.role, dst: reflect.New(typ).Elem(), report: &BindReport{}}
+		if err := nested.plan(typ, input, nil, "", inPath); err != nil {
+			return empty, err
+		}
+		b.report.Blocked = append(b.report.Blocked, nested.report.Blocked...)
+		b.report.Unknown = append(b.report.Unknown, nested.report.Unknown...)
+		if err := setFieldsWithUndo(nested.dst, nested.writes); err != nil {
+			return empty, err
+		}
+		return nested.dst, nil
+	case reflect.Slice, reflect.Array:
+		if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+			return empty, fmt.Errorf("field: %s: cannot use %s as %s", inPath, val.Type(), typ)
+		}
+		var res reflect.Value
+		if typ.Kind() == reflect.Slice {
+			res = reflect.MakeSlice(typ, val.Len(), val.Len())
+		} else if val.Len() > typ.Len() {
+			return empty, fmt.Errorf("field: %s: cannot use %d elements as %s", inPath, val.Len(), typ)
+		} else {
+			res = reflect.New(typ).Elem()
+		}
+		for i := 0; i < val.Len(); i++ {
+			elem, err := b.bindValue(val.Index(i), typ.Elem(), fmt.Sprintf("%s[%d]", inPath, i))
+			if err != nil {
+				return empty, err
+			}
+			res.Index(i).Set(elem)
+		}
+		return res, nil
+	case reflect.Map:
+		if val.Kind() != reflect.Map {
+			return empty, fmt.Errorf("field: %s: cannot use %s as %s", inPath, val.Type(), typ)
+		}
+		res := reflect.MakeMapWithSize(typ, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			k, err := convertValue(iter.Key(), typ.Key())
+			if err != nil {
+				return empty, fmt.Errorf("field: %s: key %v: %w", inPath, iter.Key(), err)
+			}
+			v, err := b.bindValue(iter.Value(), typ.Elem(), joinPath(inPath, fmt.Sprint(iter.Key())))
+			if err != nil {
+				return empty, err
+			}
+			res.SetMapIndex(k, v)
+		}
+		return res, nil
+	}
+	v, err := convertValue(val, typ)
+	if err != nil {
+		return empty, fmt.Errorf("field: %s: %w", inPath, err)
+	}
+	return v, nil
+}
+
+// hasBindTag reports whether typ is or contains, through pointers, slices, arrays, maps and struct fields,
+// a structure with a bind tag.
+func hasBindTag(typ reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[typ] {
+		return false
+	}
+	seen[typ] = true
+
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return hasBindTag(typ.Elem(), seen)
+	case reflect.Map:
+		return hasBindTag(typ.Key(), seen) || hasBindTag(typ.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if _, ok := field.Tag.Lookup("bind"); ok || hasBindTag(field.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stringMap returns v as a map[string]interface{} if it is a map with string keys.
+func stringMap(v interface{}) (map[string]interface{}, bool) {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m, true
+	}
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Map || val.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	m := make(map[string]interface{}, val.Len())
+	iter := val.MapRange()
+	for iter.Next() {
+		m[iter.Key().String()] = iter.Value().Interface()
+	}
+	return m, true
+}
+
+func (b *binder) allowed(field reflect.StructField) bool {
+	tag, ok := field.Tag.Lookup("bind")
+	if !ok || tag == "" {
+		return true
+	}
+	for _, role := range strings.Split(tag, ",") {
+		role = strings.TrimSpace(role)
+		if role == "readonly" || role == "-" {
+			return false
+		}
+		if role == b.role && b.role != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// setFieldsWithUndo performs the writes to the struct val, allocating the nil pointers on the way,
+// and if one fails, restores the fields set so far.
+func setFieldsWithUndo(val reflect.Value, writes []bindWrite) error {
+	var undo []fieldUndo
+	for _, w := range writes {
+		if err := setFieldWithUndo(val, w, &undo); err != nil {
+			for i := len(undo) - 1; i >= 0; i-- {
+				undo[i].field.Set(undo[i].old)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldUndo is the previous value of a field modified by setFieldsWithUndo.
+type fieldUndo struct {
+	field reflect.Value
+	old   reflect.Value
+}
+
+func newFieldUndo(field reflect.Value) fieldUndo {
+	old := reflect.New(field.Type()).Elem()
+	old.Set(field)
+	return fieldUndo{field: field, old: old}
+}
+
+// setFieldWithUndo records the values which the write will modify, and performs it.
+// A panic is returned as an error.
+func setFieldWithUndo(val reflect.Value, w bindWrite, undo *[]fieldUndo) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("field: %s: %v", w.path, r)
+		}
+	}()
+
+	field, err := fieldByIndex(val, w.index, func(ptr reflect.Value) reflect.Value {
+		// restoring the nil pointer drops the nested fields as well
+		*undo = append(*undo, newFieldUndo(ptr))
+		return allocPointer(ptr)
+	})
+	if err != nil {
+		return err
+	}
+	*undo = append(*undo, newFieldUndo(field))
+	field.Set(w.value)
+	return nil
+}
+
+// checkFieldIndex checks that the field of the struct val at index can be written by setFieldsWithUndo,
+// without modifying val.
+func checkFieldIndex(val reflect.Value, index []int) error {
+	_, err := fieldByIndex(val, index, func(ptr reflect.Value) reflect.Value {
+		return reflect.New(ptr.Type().Elem()).Elem()
+	})
+	return err
+}
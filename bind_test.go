@@ -0,0 +1,169 @@
+package xreflect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bindProfile struct {
+	Bio      string `json:"bio"`
+	Verified bool   `json:"verified" bind:"admin"`
+}
+
+type bindAccount struct {
+	ID      int          `json:"id" bind:"readonly"`
+	Name    string       `json:"name"`
+	Age     int          `json:"age"`
+	Role    string       `json:"role" bind:"admin,owner"`
+	Balance float64      `json:"balance" bind:"-"`
+	Profile *bindProfile `json:"profile"`
+	Limits  struct {
+		Max int `json:"max"`
+	} `json:"limits" bind:"admin"`
+	Extra  interface{} `json:"extra"`
+	secret string
+}
+
+func TestBindAllowed(t *testing.T) {
+	_, err := BindAllowed(nil, nil, "")
+	assert.EqualError(t, err, "dst must not be nil")
+	_, err = BindAllowed(bindAccount{}, nil, "")
+	assert.EqualError(t, err, "dst must be struct pointer")
+
+	input := map[string]interface{}{
+		"id":      float64(2),
+		"name":    "bob",
+		"Age":     "42",
+		"role":    "admin",
+		"balance": 1e6,
+		"profile": map[string]interface{}{"bio": "hi", "verified": true},
+		"limits":  map[string]interface{}{"max": 10},
+		"extra":   nil,
+		"secret":  "x",
+		"nope":    1,
+	}
+
+	a := &bindAccount{ID: 1, Extra: 1}
+	report, err := BindAllowed(a, input, "user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"Age", "extra", "name", "profile.bio"}, report.Bound)
+	assert.Equal(t, []string{"balance", "id", "limits", "profile.verified", "role"}, report.Blocked)
+	assert.Equal(t, []string{"nope", "secret"}, report.Unknown)
+	assert.Equal(t, &bindAccount{ID: 1, Name: "bob", Age: 42, Profile: &bindProfile{Bio: "hi"}}, a)
+
+	a = &bindAccount{}
+	report, err = BindAllowed(a, input, "admin")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"balance", "id"}, report.Blocked)
+	assert.Equal(t, "admin", a.Role)
+	assert.Equal(t, 10, a.Limits.Max)
+	assert.Equal(t, true, a.Profile.Verified)
+	assert.Equal(t, 0, a.ID)
+
+	a = &bindAccount{Name: "old"}
+	_, err = BindAllowedStrict(a, input, "owner")
+	assert.EqualError(t, err, "blocked fields: balance, id, limits, profile.verified")
+	assert.Equal(t, "old", a.Name)
+
+	report, err = BindAllowedStrict(a, map[string]interface{}{"role": "owner", "name": "new"}, "owner")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"name", "role"}, report.Bound)
+	assert.Equal(t, "new", a.Name)
+
+	_, err = BindAllowed(a, map[string]interface{}{"name": "newer", "age": "x"}, "user")
+	assert.EqualError(t, err, `field: age: cannot use string "x" as int`)
+	assert.Equal(t, "new", a.Name)
+}
+
+type BindAudit struct {
+	CreatedBy string `json:"created_by"`
+}
+
+type bindItem struct {
+	SKU   string  `json:"sku"`
+	Price float64 `json:"price" bind:"admin"`
+}
+
+type bindOrder struct {
+	*BindAudit `bind:"readonly"`
+	Items      []bindItem          `json:"items"`
+	Named      map[string]bindItem `json:"named"`
+	Main       *bindProfile        `json:"main"`
+}
+
+func TestBindAllowedNested(t *testing.T) {
+	input := map[string]interface{}{
+		"created_by": "mallory",
+		"CreatedBy":  "mallory",
+		"items": []interface{}{
+			map[string]interface{}{"sku": "a", "price": 0},
+			map[string]string{"sku": "b"},
+		},
+		"named": map[string]interface{}{"x": map[string]interface{}{"sku": "c", "price": 1}},
+		"main":  map[string]string{"bio": "hi", "verified": "true"},
+	}
+	o := &bindOrder{}
+	report, err := BindAllowed(o, input, "user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"items", "main.bio", "named"}, report.Bound)
+	assert.Equal(t, []string{"CreatedBy", "created_by", "items[0].price", "main.verified", "named.x.price"},
+		report.Blocked)
+	assert.Equal(t, &bindOrder{
+		Items: []bindItem{{SKU: "a"}, {SKU: "b"}},
+		Named: map[string]bindItem{"x": {SKU: "c"}},
+		Main:  &bindProfile{Bio: "hi"},
+	}, o)
+
+	o = &bindOrder{}
+	report, err = BindAllowed(o, input, "admin")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"CreatedBy", "created_by"}, report.Blocked)
+	assert.Equal(t, 1.0, o.Named["x"].Price)
+	assert.Equal(t, true, o.Main.Verified)
+	assert.Nil(t, o.BindAudit)
+
+	_, err = BindAllowed(o, map[string]interface{}{"items": []bindItem{{Price: 1}}}, "user")
+	assert.EqualError(t, err, "field: items[0]: cannot use xreflect.bindItem as xreflect.bindItem, fields must be given as map")
+	_, err = BindAllowed(o, map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"price": "x"}},
+	}, "admin")
+	assert.EqualError(t, err, `field: items[0].price: cannot use string "x" as float64`)
+	_, err = BindAllowed(o, map[string]interface{}{"main": bindProfile{Verified: true}}, "user")
+	assert.EqualError(t, err, "field: main: cannot use xreflect.bindProfile as xreflect.bindProfile, fields must be given as map")
+}
+
+type bindInner struct {
+	X int `json:"x"`
+}
+
+type bindPlain struct {
+	bindInner
+	Name  string `json:"name"`
+	Token string `json:"-"`
+}
+
+type bindPointer struct {
+	Name string `json:"name"`
+	*bindInner
+}
+
+func TestBindAllowedEmbedded(t *testing.T) {
+	p := &bindPlain{Token: "t"}
+	report, err := BindAllowed(p, map[string]interface{}{"name": "new", "x": 5, "Token": "x", "token": "x"}, "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"name", "x"}, report.Bound)
+	assert.Equal(t, []string{"Token", "token"}, report.Unknown)
+	assert.Equal(t, &bindPlain{bindInner: bindInner{X: 5}, Name: "new", Token: "t"}, p)
+
+	o := &bindPointer{Name: "old"}
+	report, err = BindAllowed(o, map[string]interface{}{"name": "new", "x": 5}, "")
+	assert.EqualError(t, err, "field: x: field: bindInner can not set")
+	assert.Equal(t, []string{"name"}, report.Bound)
+	assert.Equal(t, &bindPointer{Name: "old"}, o)
+
+	o = &bindPointer{Name: "old", bindInner: &bindInner{}}
+	_, err = BindAllowed(o, map[string]interface{}{"name": "new", "x": 5}, "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &bindPointer{Name: "new", bindInner: &bindInner{X: 5}}, o)
+}
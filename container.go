@@ -0,0 +1,339 @@
+package xreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// In can be embedded in a struct used as a parameter of a constructor or of the function passed to
+// Container.Invoke, its exported fields are then resolved individually instead of the struct itself.
+// The fields support the tags `name:"..."` to resolve a value provided by Container.ProvideNamed,
+// `group:"..."` to resolve a slice of all values provided by Container.ProvideGroup,
+// and `optional:"true"` to use the zero value if there is no provider.
+type In struct{}
+
+var inType = reflect.TypeOf(In{})
+
+// Container is a small dependency injection container. Constructors are registered by Provide, and Invoke calls
+// a function with its parameters resolved by type, lazily calling each constructor at most once, so all values
+// are singletons. It is safe for concurrent use, but constructors must not use the container themselves.
+type Container struct {
+	mu        sync.Mutex
+	providers map[depKey]*provider
+	groups    map[depKey][]*provider
+	all       []*provider
+}
+
+type depKey struct {
+	typ   reflect.Type
+	name  string
+	group string
+}
+
+func (k depKey) String() string {
+	switch {
+	case k.name != "":
+		return fmt.Sprintf("%s[name=%s]", k.typ, k.name)
+	case k.group != "":
+		return fmt.Sprintf("%s[group=%s]", k.typ, k.group)
+	default:
+		return k.typ.String()
+	}
+}
+
+type depParam struct {
+	key      depKey
+	optional bool
+	// field is the name of the In struct field, empty for plain parameters.
+	field string
+}
+
+type provider struct {
+	fn      reflect.Value
+	keys    []depKey
+	called  bool
+	results []reflect.Value
+}
+
+// NewContainer returns an empty Container.
+func NewContainer() *Container {
+	return &Container{
+		providers: make(map[depKey]*provider),
+		groups:    make(map[depKey][]*provider),
+	}
+}
+
+// Provide registers constructor, a function whose parameters are resolved from the container and whose results,
+// except a trailing error, are provided by type, e.g. func(*Config) (*DB, error).
+// Each type can only be provided once.
+func (c *Container) Provide(constructor interface{}) error {
+	return c.provide(constructor, "", "")
+}
+
+// ProvideNamed has the same functionality as Provide, but the results are provided under name,
+// and can only be resolved by fields of In structs with the `name:"..."` tag.
+func (c *Container) ProvideNamed(name string, constructor interface{}) error {
+	if name == "" {
+		return errors.New("name must not be empty")
+	}
+	return c.provide(constructor, name, "")
+}
+
+// ProvideGroup has the same functionality as Provide, but the results are added to group. Any number of
+// constructors can provide the same type to a group, fields of In structs with the `group:"..."` tag and
+// a slice type receive all of them, in registration order.
+func (c *Container) ProvideGroup(group string, constructor interface{}) error {
+	if group == "" {
+		return errors.New("group must not be empty")
+	}
+	return c.provide(constructor, "", group)
+}
+
+func (c *Container) provide(constructor interface{}, name, group string) error {
+	if constructor == nil {
+		return errors.New("constructor must not be nil")
+	}
+	val := reflect.ValueOf(constructor)
+	if val.Kind() != reflect.Func || val.IsNil() {
+		return errors.New("constructor must be func")
+	}
+	typ := val.Type()
+	if typ.IsVariadic() {
+		return errors.New("constructor must not be variadic")
+	}
+
+	p := &provider{fn: val}
+	for i := 0; i < typ.NumOut(); i++ {
+		if typ.Out(i) == errorType {
+			if i != typ.NumOut()-1 {
+				return errors.New("constructor error result must be the last one")
+			}
+			continue
+		}
+		p.keys = append(p.keys, depKey{typ: typ.Out(i), name: name, group: group})
+	}
+	if len(p.keys) == 0 {
+		return errors.New("constructor must return at least one value")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if group == "" {
+		for _, key := range p.keys {
+			if _, ok := c.providers[key]; ok {
+				return fmt.Errorf("%s is already provided", key)
+			}
+		}
+	}
+	for _, key := range p.keys {
+		if group == "" {
+			c.providers[key] = p
+		} else {
+			c.groups[key] = append(c.groups[key], p)
+		}
+	}
+	c.all = append(c.all, p)
+	return nil
+}
+
+// Invoke calls fn with its parameters resolved from the container, constructing the missing values.
+// It returns the results of fn in the same way as CallFunc. If a dependency cannot be resolved, the error
+// contains the chain of dependencies, e.g. "dependency cycle: *A -> *B -> *A".
+func (c *Container) Invoke(fn interface{}) ([]reflect.Value, error) {
+	if fn == nil {
+		return nil, errors.New("fn must not be nil")
+	}
+	val := reflect.ValueOf(fn)
+	if val.Kind() != reflect.Func {
+		return nil, errors.New("fn must be func")
+	}
+	if val.Type().IsVariadic() {
+		return nil, errors.New("fn must not be variadic")
+	}
+
+	c.mu.Lock()
+	args, err := c.resolveParams(val.Type(), nil)
+	c.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return CallFunc(fn, args...)
+}
+
+// Graph returns a dump of the dependency graph for debugging, one line per constructor in registration order,
+// such as "*DB <- *Config", followed by " (constructed)" if the constructor has been called.
+func (c *Container) Graph() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var lines []string
+	for _, p := range c.all {
+		var out []string
+		for _, key := range p.keys {
+			out = append(out, key.String())
+		}
+		var in []string
+		for _, param := range dependencies(p.fn.Type()) {
+			s := param.key.String()
+			if param.key.group != "" {
+				s = "[]" + s
+			}
+			if param.optional {
+				s += "?"
+			}
+			in = append(in, s)
+		}
+
+		line := strings.Join(out, ", ")
+		if len(in) > 0 {
+			line += " <- " + strings.Join(in, ", ")
+		}
+		if p.called {
+			line += " (constructed)"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *Container) resolveParams(typ reflect.Type, chain []depKey) ([]interface{}, error) {
+	args := make([]interface{}, typ.NumIn())
+	for i := range args {
+		in := typ.In(i)
+		if !isInStruct(in) {
+			v, err := c.resolve(depParam{key: depKey{typ: in}}, chain)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v.Interface()
+			continue
+		}
+
+		s := reflect.New(in).Elem()
+		for _, param := range dependencies(in) {
+			field := s.FieldByName(param.field)
+			if param.key.group != "" && field.Kind() != reflect.Slice {
+				return nil, fmt.Errorf("field: %s with group tag must be slice", param.field)
+			}
+			v, err := c.resolve(param, chain)
+			if err != nil {
+				return nil, err
+			}
+			field.Set(v)
+		}
+		args[i] = s.Interface()
+	}
+	return args, nil
+}
+
+func (c *Container) resolve(param depParam, chain []depKey) (reflect.Value, error) {
+	key := param.key
+	if key.group != "" {
+		res := reflect.MakeSlice(reflect.SliceOf(key.typ), 0, len(c.groups[key]))
+		for _, p := range c.groups[key] {
+			v, err := c.construct(p, key, chain)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			res = reflect.Append(res, v)
+		}
+		return res, nil
+	}
+
+	p, ok := c.providers[key]
+	if !ok {
+		if param.optional {
+			return reflect.Zero(key.typ), nil
+		}
+		if len(chain) == 0 {
+			return reflect.Value{}, fmt.Errorf("no provider for %s", key)
+		}
+		return reflect.Value{}, fmt.Errorf("no provider for %s: required by %s", key, chainString(chain))
+	}
+	return c.construct(p, key, chain)
+}
+
+func (c *Container) construct(p *provider, key depKey, chain []depKey) (reflect.Value, error) {
+	if !p.called {
+		for _, k := range chain {
+			if k == key {
+				return reflect.Value{}, fmt.Errorf("dependency cycle: %s -> %s", chainString(chain), key)
+			}
+		}
+
+		args, err := c.resolveParams(p.fn.Type(), append(chain[:len(chain):len(chain)], key))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		res, err := CallFuncResult(p.fn, args...)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("construct %s: %w", key, err)
+		}
+		if res.Err() != nil {
+			return reflect.Value{}, fmt.Errorf("construct %s: %w", key, res.Err())
+		}
+		p.called, p.results = true, res.Values()
+	}
+
+	for i, k := range p.keys {
+		if k == key {
+			return p.results[i], nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("no provider for %s", key)
+}
+
+// dependencies returns the parameters of the func or In struct type typ.
+func dependencies(typ reflect.Type) []depParam {
+	var params []depParam
+	if typ.Kind() == reflect.Func {
+		for i := 0; i < typ.NumIn(); i++ {
+			if isInStruct(typ.In(i)) {
+				params = append(params, dependencies(typ.In(i))...)
+				continue
+			}
+			params = append(params, depParam{key: depKey{typ: typ.In(i)}})
+		}
+		return params
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Type == inType || !field.IsExported() {
+			continue
+		}
+		param := depParam{
+			key:      depKey{typ: field.Type, name: field.Tag.Get("name"), group: field.Tag.Get("group")},
+			optional: field.Tag.Get("optional") == "true",
+			field:    field.Name,
+		}
+		if param.key.group != "" && field.Type.Kind() == reflect.Slice {
+			param.key.typ = field.Type.Elem()
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+func isInStruct(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if field := typ.Field(i); field.Anonymous && field.Type == inType {
+			return true
+		}
+	}
+	return false
+}
+
+func chainString(chain []depKey) string {
+	s := make([]string, len(chain))
+	for i, key := range chain {
+		s[i] = key.String()
+	}
+	return strings.Join(s, " -> ")
+}
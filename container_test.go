@@ -0,0 +1,149 @@
+package xreflect
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	diConfig struct {
+		DSN string
+	}
+	diDB struct {
+		Config *diConfig
+	}
+	diCache struct {
+		Name string
+	}
+	diRoute struct {
+		Path string
+	}
+	diServer struct {
+		DB     *diDB
+		Routes []*diRoute
+	}
+	diCycleA struct{}
+	diCycleB struct{}
+)
+
+type diServerParams struct {
+	In
+
+	DB      *diDB
+	Primary *diCache   `name:"primary"`
+	Replica *diCache   `name:"replica" optional:"true"`
+	Routes  []*diRoute `group:"routes"`
+	Logger  *diCycleA  `optional:"true"`
+}
+
+func TestContainerProvide(t *testing.T) {
+	c := NewContainer()
+	assert.EqualError(t, c.Provide(nil), "constructor must not be nil")
+	assert.EqualError(t, c.Provide(1), "constructor must be func")
+	assert.EqualError(t, c.Provide(func(...int) *diDB { return nil }), "constructor must not be variadic")
+	assert.EqualError(t, c.Provide(func() {}), "constructor must return at least one value")
+	assert.EqualError(t, c.Provide(func() (error, *diDB) { return nil, nil }),
+		"constructor error result must be the last one")
+	assert.EqualError(t, c.ProvideNamed("", func() *diDB { return nil }), "name must not be empty")
+	assert.EqualError(t, c.ProvideGroup("", func() *diDB { return nil }), "group must not be empty")
+
+	assert.Equal(t, nil, c.Provide(func() *diConfig { return &diConfig{} }))
+	assert.EqualError(t, c.Provide(func() *diConfig { return nil }), "*xreflect.diConfig is already provided")
+	assert.Equal(t, nil, c.ProvideNamed("a", func() *diConfig { return nil }))
+	assert.Equal(t, nil, c.ProvideGroup("g", func() *diConfig { return nil }))
+	assert.Equal(t, nil, c.ProvideGroup("g", func() *diConfig { return nil }))
+}
+
+func TestContainerInvoke(t *testing.T) {
+	c := NewContainer()
+	configCalls := 0
+	_ = c.Provide(func() *diConfig {
+		configCalls++
+		return &diConfig{DSN: "dsn"}
+	})
+	_ = c.Provide(func(cfg *diConfig) (*diDB, error) {
+		return &diDB{Config: cfg}, nil
+	})
+	_ = c.ProvideNamed("primary", func() *diCache { return &diCache{Name: "primary"} })
+	_ = c.ProvideGroup("routes", func() *diRoute { return &diRoute{Path: "/a"} })
+	_ = c.ProvideGroup("routes", func(db *diDB) *diRoute { return &diRoute{Path: "/b"} })
+	_ = c.Provide(func(p diServerParams) *diServer {
+		return &diServer{DB: p.DB, Routes: p.Routes}
+	})
+
+	_, err := c.Invoke(nil)
+	assert.EqualError(t, err, "fn must not be nil")
+	_, err = c.Invoke(1)
+	assert.EqualError(t, err, "fn must be func")
+
+	var db1, db2 *diDB
+	res, err := c.Invoke(func(db *diDB, cfg *diConfig) string {
+		db1 = db
+		return cfg.DSN
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "dsn", res[0].Interface())
+	_, err = c.Invoke(func(db *diDB) { db2 = db })
+	assert.Equal(t, nil, err)
+	assert.Same(t, db1, db2)
+	assert.Equal(t, 1, configCalls)
+
+	res, err = c.Invoke(func(p diServerParams, s *diServer) int {
+		assert.Equal(t, "primary", p.Primary.Name)
+		assert.Nil(t, p.Replica)
+		assert.Nil(t, p.Logger)
+		assert.Same(t, db1, s.DB)
+		return len(s.Routes)
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, res[0].Interface())
+
+	_, err = c.Invoke(func(a *diCycleB) {})
+	assert.EqualError(t, err, "no provider for *xreflect.diCycleB")
+
+	_, err = c.Invoke(func(p struct {
+		In
+		Cache *diCache `name:"replica"`
+	}) {
+	})
+	assert.EqualError(t, err, "no provider for *xreflect.diCache[name=replica]")
+
+	_, err = c.Invoke(func(p struct {
+		In
+		Route *diRoute `group:"routes"`
+	}) {
+	})
+	assert.EqualError(t, err, "field: Route with group tag must be slice")
+
+	assert.Equal(t, "*xreflect.diConfig (constructed)\n"+
+		"*xreflect.diDB <- *xreflect.diConfig (constructed)\n"+
+		"*xreflect.diCache[name=primary] (constructed)\n"+
+		"*xreflect.diRoute[group=routes] (constructed)\n"+
+		"*xreflect.diRoute[group=routes] <- *xreflect.diDB (constructed)\n"+
+		"*xreflect.diServer <- *xreflect.diDB, *xreflect.diCache[name=primary], *xreflect.diCache[name=replica]?, "+
+		"[]*xreflect.diRoute[group=routes], *xreflect.diCycleA? (constructed)", c.Graph())
+}
+
+func TestContainerErrors(t *testing.T) {
+	c := NewContainer()
+	_ = c.Provide(func(b *diCycleB) *diCycleA { return nil })
+	_ = c.Provide(func(a *diCycleA) *diCycleB { return nil })
+	_ = c.Provide(func(cache *diCache) *diDB { return nil })
+	_ = c.Provide(func() (*diConfig, error) { return nil, errors.New("bad config") })
+	_ = c.Provide(func(cfg *diConfig) *diServer { return nil })
+
+	_, err := c.Invoke(func(a *diCycleA) {})
+	assert.EqualError(t, err, "dependency cycle: *xreflect.diCycleA -> *xreflect.diCycleB -> *xreflect.diCycleA")
+
+	_, err = c.Invoke(func(db *diDB) {})
+	assert.EqualError(t, err, "no provider for *xreflect.diCache: required by *xreflect.diDB")
+
+	_, err = c.Invoke(func(s *diServer) {})
+	assert.EqualError(t, err, "construct *xreflect.diConfig: bad config")
+
+	res, err := c.Invoke(func() error { return errors.New("invoke error") })
+	assert.EqualError(t, err, "invoke error")
+	assert.Equal(t, 0, len(res))
+}
@@ -0,0 +1,310 @@
+package xreflect
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// convertValue converts val to a value of type typ.
+// Besides assignability and the conversions supported by reflect.Value.Convert between types of the same kind,
+// it converts between numeric kinds (rejecting overflow and lost fractions), parses strings into numbers and bools,
+// takes and dereferences pointers, and converts slices, maps and map[string]interface{} to structs element-wise.
+// An invalid val, i.e. nil, is converted to the zero value of typ if typ can be nil.
+func convertValue(val reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	var empty reflect.Value
+	if !val.IsValid() {
+		if !isNillableKind(typ.Kind()) {
+			return empty, fmt.Errorf("cannot use nil as %s", typ)
+		}
+		return reflect.Zero(typ), nil
+	}
+
+	if val.Type().AssignableTo(typ) {
+		return val, nil
+	}
+	if val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return convertValue(empty, typ)
+		}
+		return convertValue(val.Elem(), typ)
+	}
+	if typ.Kind() == reflect.Interface {
+		return empty, fmt.Errorf("cannot use %s as %s: missing methods", val.Type(), typ)
+	}
+
+	if val.Kind() == reflect.Pointer && typ.Kind() != reflect.Pointer {
+		if val.IsNil() {
+			return empty, fmt.Errorf("cannot use nil %s as %s", val.Type(), typ)
+		}
+		return convertValue(val.Elem(), typ)
+	}
+	if typ.Kind() == reflect.Pointer {
+		if val.Kind() == reflect.Pointer && val.IsNil() {
+			return reflect.Zero(typ), nil
+		}
+		elem, err := convertValue(val, typ.Elem())
+		if err != nil {
+			return empty, err
+		}
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+
+	switch {
+	case isNumberKind(val.Kind()) && isNumberKind(typ.Kind()):
+		return convertNumber(val, typ)
+	case val.Kind() == reflect.String && (isNumberKind(typ.Kind()) || typ.Kind() == reflect.Bool):
+		return parseString(val.String(), typ)
+	case val.Kind() == typ.Kind() && val.Type().ConvertibleTo(typ):
+		return val.Convert(typ), nil
+	case val.Kind() == reflect.String && typ.Kind() == reflect.Slice && val.Type().ConvertibleTo(typ):
+		return val.Convert(typ), nil
+	case (val.Kind() == reflect.Slice || val.Kind() == reflect.Array) && typ.Kind() == reflect.Slice:
+		res := reflect.MakeSlice(typ, val.Len(), val.Len())
+		for i := 0; i < val.Len(); i++ {
+			elem, err := convertValue(val.Index(i), typ.Elem())
+			if err != nil {
+				return empty, fmt.Errorf("index %d: %w", i, err)
+			}
+			res.Index(i).Set(elem)
+		}
+		return res, nil
+	case val.Kind() == reflect.Map && typ.Kind() == reflect.Map:
+		if val.IsNil() {
+			return reflect.Zero(typ), nil
+		}
+		res := reflect.MakeMapWithSize(typ, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			k, err := convertValue(iter.Key(), typ.Key())
+			if err != nil {
+				return empty, fmt.Errorf("key %v: %w", iter.Key(), err)
+			}
+			v, err := convertValue(iter.Value(), typ.Elem())
+			if err != nil {
+				return empty, fmt.Errorf("key %v: %w", iter.Key(), err)
+			}
+			res.SetMapIndex(k, v)
+		}
+		return res, nil
+	case val.Kind() == reflect.Map && val.Type().Key().Kind() == reflect.String && typ.Kind() == reflect.Struct:
+		return convertMapToStruct(val, typ)
+	}
+
+	return empty, fmt.Errorf("cannot use %s as %s", val.Type(), typ)
+}
+
+// convertArg converts a call argument to the parameter type typ like convertValue, but a nil argument is
+// converted to the zero value of any type, and pointers are neither taken nor dereferenced, because a function
+// writing through its pointer parameter would silently write to a copy. Only a map can still be converted to
+// a pointer to a new struct, since it is not the value the function writes to anyway.
+func convertArg(val reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	if !val.IsValid() {
+		return reflect.Zero(typ), nil
+	}
+	if val.Kind() == reflect.Interface && !val.IsNil() {
+		val = val.Elem()
+	}
+	if !val.Type().AssignableTo(typ) && typ.Kind() != reflect.Interface {
+		if val.Kind() == reflect.Pointer || typ.Kind() == reflect.Pointer && val.Kind() != reflect.Map {
+			return reflect.Value{}, fmt.Errorf("cannot use %s as %s", val.Type(), typ)
+		}
+	}
+	return convertValue(val, typ)
+}
+
+func convertNumber(val reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	var empty reflect.Value
+	res := reflect.New(typ).Elem()
+
+	switch {
+	case isIntKind(val.Kind()):
+		i := val.Int()
+		switch {
+		case isIntKind(typ.Kind()):
+			if res.OverflowInt(i) {
+				return empty, fmt.Errorf("cannot use %s %d as %s: overflow", val.Type(), i, typ)
+			}
+		case isUintKind(typ.Kind()):
+			if i < 0 || res.OverflowUint(uint64(i)) {
+				return empty, fmt.Errorf("cannot use %s %d as %s: overflow", val.Type(), i, typ)
+			}
+		}
+	case isUintKind(val.Kind()):
+		u := val.Uint()
+		switch {
+		case isIntKind(typ.Kind()):
+			if u > math.MaxInt64 || res.OverflowInt(int64(u)) {
+				return empty, fmt.Errorf("cannot use %s %d as %s: overflow", val.Type(), u, typ)
+			}
+		case isUintKind(typ.Kind()):
+			if res.OverflowUint(u) {
+				return empty, fmt.Errorf("cannot use %s %d as %s: overflow", val.Type(), u, typ)
+			}
+		}
+	case isFloatKind(val.Kind()):
+		f := val.Float()
+		switch {
+		case isIntKind(typ.Kind()), isUintKind(typ.Kind()):
+			if f != math.Trunc(f) {
+				return empty, fmt.Errorf("cannot use %s %v as %s: fraction lost", val.Type(), f, typ)
+			}
+			// compare with the exact limits 2^63 and 2^64 first, float64(math.MaxInt64) rounds up to 2^63
+			if isIntKind(typ.Kind()) && (f < -(1<<63) || f >= 1<<63 || res.OverflowInt(int64(f))) ||
+				isUintKind(typ.Kind()) && (f < 0 || f >= 1<<64 || res.OverflowUint(uint64(f))) {
+				return empty, fmt.Errorf("cannot use %s %v as %s: overflow", val.Type(), f, typ)
+			}
+		case isFloatKind(typ.Kind()):
+			if res.OverflowFloat(f) {
+				return empty, fmt.Errorf("cannot use %s %v as %s: overflow", val.Type(), f, typ)
+			}
+		}
+	}
+
+	res.Set(val.Convert(typ))
+	return res, nil
+}
+
+func parseString(s string, typ reflect.Type) (reflect.Value, error) {
+	var empty reflect.Value
+	res := reflect.New(typ).Elem()
+
+	var err error
+	switch {
+	case typ.Kind() == reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+			res.SetBool(b)
+		}
+	case isIntKind(typ.Kind()):
+		var i int64
+		if i, err = strconv.ParseInt(strings.TrimSpace(s), 10, typ.Bits()); err == nil {
+			res.SetInt(i)
+		}
+	case isUintKind(typ.Kind()):
+		var u uint64
+		if u, err = strconv.ParseUint(strings.TrimSpace(s), 10, typ.Bits()); err == nil {
+			res.SetUint(u)
+		}
+	case isFloatKind(typ.Kind()):
+		var f float64
+		if f, err = strconv.ParseFloat(strings.TrimSpace(s), typ.Bits()); err == nil {
+			res.SetFloat(f)
+		}
+	default:
+		err = strconv.ErrSyntax
+	}
+	if err != nil {
+		return empty, fmt.Errorf("cannot use string %q as %s", s, typ)
+	}
+	return res, nil
+}
+
+// convertMapToStruct converts a map with string keys to the struct type typ. Keys are matched against
+// the field names, json tag names, or case-insensitively against the field names. Unknown keys are ignored.
+func convertMapToStruct(val reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	var empty reflect.Value
+	res := reflect.New(typ).Elem()
+
+	iter := val.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		field, ok := fieldByTagOrName(typ, key, "json")
+		if !ok || field.PkgPath != "" {
+			continue
+		}
+		v, err := convertValue(iter.Value(), field.Type)
+		if err != nil {
+			return empty, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		f, err := fieldByIndex(res, field.Index, allocPointer)
+		if err != nil {
+			return empty, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		f.Set(v)
+	}
+	return res, nil
+}
+
+// fieldByIndex returns the nested field of the struct val at index like reflect.Value.FieldByIndex, but each
+// nil pointer to a structure on the way, such as an embedded pointer of a promoted field, is passed to alloc,
+// which returns the structure to continue with. An error is returned if such a pointer cannot be set.
+func fieldByIndex(val reflect.Value, index []int, alloc func(ptr reflect.Value) reflect.Value) (reflect.Value, error) {
+	var name string
+	for _, i := range index {
+		if val.Kind() == reflect.Pointer {
+			if val.IsNil() {
+				if !val.CanSet() {
+					return reflect.Value{}, fmt.Errorf("field: %s can not set", name)
+				}
+				val = alloc(val)
+			} else {
+				val = val.Elem()
+			}
+		}
+		name = val.Type().Field(i).Name
+		val = val.Field(i)
+	}
+	return val, nil
+}
+
+// allocPointer sets the nil pointer ptr to a new value, and returns it.
+func allocPointer(ptr reflect.Value) reflect.Value {
+	ptr.Set(reflect.New(ptr.Type().Elem()))
+	return ptr.Elem()
+}
+
+// fieldByTagOrName returns the field of the struct type typ which has the given name, the given tagKey
+// tag name, or the given name case-insensitively, in this order of precedence.
+func fieldByTagOrName(typ reflect.Type, name, tagKey string) (reflect.StructField, bool) {
+	if field, ok := typ.FieldByName(name); ok {
+		return field, true
+	}
+
+	var fold *reflect.StructField
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if tagName(field, tagKey) == name {
+			return field, true
+		}
+		if fold == nil && strings.EqualFold(field.Name, name) {
+			fold = &field
+		}
+	}
+	if fold != nil {
+		return *fold, true
+	}
+	return reflect.StructField{}, false
+}
+
+// tagName returns the name part of the tagKey tag of field, e.g. "name" for `json:"name,omitempty"`.
+func tagName(field reflect.StructField, tagKey string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(tagKey), ",")
+	return name
+}
+
+func isNillableKind(k reflect.Kind) bool {
+	return isSupportedKind(k, []reflect.Kind{reflect.Interface, reflect.Pointer, reflect.Map, reflect.Func,
+		reflect.Slice, reflect.Chan, reflect.UnsafePointer})
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	return isIntKind(k) || isUintKind(k) || isFloatKind(k)
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return isSupportedKind(k, []reflect.Kind{reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64})
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return isSupportedKind(k, []reflect.Kind{reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr})
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return isSupportedKind(k, []reflect.Kind{reflect.Float32, reflect.Float64})
+}
@@ -0,0 +1,121 @@
+package xreflect
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MyString string
+
+type convertPoint struct {
+	X    int     `json:"x"`
+	Y    float64 `json:"y"`
+	Tags []string
+	z    int
+}
+
+func TestConvertValue(t *testing.T) {
+	one := 1
+	var nilInt *int
+	var stringer fmt.Stringer
+
+	tests := []struct {
+		name  string
+		value interface{}
+		typ   reflect.Type
+		want  interface{}
+	}{
+		{"assignable", 1, reflect.TypeOf(0), 1},
+		{"int to int64", 1, reflect.TypeOf(int64(0)), int64(1)},
+		{"int64 to uint8", int64(255), reflect.TypeOf(uint8(0)), uint8(255)},
+		{"float64 to int", float64(3), reflect.TypeOf(0), 3},
+		{"float64 to int64 min", float64(-1 << 63), reflect.TypeOf(int64(0)), int64(-1 << 63)},
+		{"float64 to uint64", float64(1 << 63), reflect.TypeOf(uint64(0)), uint64(1 << 63)},
+		{"int to float32", 3, reflect.TypeOf(float32(0)), float32(3)},
+		{"float64 to named", float64(3), reflect.TypeOf(Celsius(0)), Celsius(3)},
+		{"string to int", "42", reflect.TypeOf(0), 42},
+		{"string to uint16", " 42 ", reflect.TypeOf(uint16(0)), uint16(42)},
+		{"string to float", "1.5", reflect.TypeOf(float64(0)), 1.5},
+		{"string to bool", "true", reflect.TypeOf(false), true},
+		{"string to named", "a", reflect.TypeOf(MyString("")), MyString("a")},
+		{"string to bytes", "a", reflect.TypeOf([]byte{}), []byte("a")},
+		{"value to pointer", 1, reflect.TypeOf(&one), &one},
+		{"pointer to value", &one, reflect.TypeOf(int64(0)), int64(1)},
+		{"nil pointer to pointer", nilInt, reflect.TypeOf(&one), nilInt},
+		{"slice", []interface{}{1, "2"}, reflect.TypeOf([]int{}), []int{1, 2}},
+		{"array to slice", [2]int{1, 2}, reflect.TypeOf([]int64{}), []int64{1, 2}},
+		{"map", map[string]interface{}{"a": 1.0}, reflect.TypeOf(map[string]int{}), map[string]int{"a": 1}},
+		{"map to struct", map[string]interface{}{"x": 1.0, "Y": 2, "tags": []interface{}{"a"}, "z": 3, "w": 4},
+			reflect.TypeOf(convertPoint{}), convertPoint{X: 1, Y: 2, Tags: []string{"a"}}},
+		{"nil to interface", nil, reflect.TypeOf(&stringer).Elem(), stringer},
+		{"nil to map", nil, reflect.TypeOf(map[string]int{}), map[string]int(nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertValue(reflect.ValueOf(tt.value), tt.typ)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tt.typ, got.Type())
+			assert.Equal(t, tt.want, got.Interface())
+		})
+	}
+
+	f, err := convertValue(reflect.ValueOf(nil), reflect.TypeOf(func() {}))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, f.IsNil())
+
+	errTests := []struct {
+		name  string
+		value interface{}
+		typ   reflect.Type
+		err   string
+	}{
+		{"nil to int", nil, reflect.TypeOf(0), "cannot use nil as int"},
+		{"nil pointer to int", nilInt, reflect.TypeOf(0), "cannot use nil *int as int"},
+		{"overflow", 256, reflect.TypeOf(uint8(0)), "cannot use int 256 as uint8: overflow"},
+		{"negative to uint", -1, reflect.TypeOf(uint(0)), "cannot use int -1 as uint: overflow"},
+		{"float to int64 overflow", float64(1 << 63), reflect.TypeOf(int64(0)),
+			"cannot use float64 9.223372036854776e+18 as int64: overflow"},
+		{"float to uint64 overflow", float64(1 << 64), reflect.TypeOf(uint64(0)),
+			"cannot use float64 1.8446744073709552e+19 as uint64: overflow"},
+		{"fraction", 1.5, reflect.TypeOf(0), "cannot use float64 1.5 as int: fraction lost"},
+		{"int to string", 65, reflect.TypeOf(""), "cannot use int as string"},
+		{"bad string", "abc", reflect.TypeOf(0), "cannot use string \"abc\" as int"},
+		{"missing methods", 1, reflect.TypeOf(&stringer).Elem(), "cannot use int as fmt.Stringer: missing methods"},
+		{"slice element", []interface{}{1, "a"}, reflect.TypeOf([]int{}), "index 1: cannot use string \"a\" as int"},
+		{"struct field", map[string]interface{}{"x": "a"}, reflect.TypeOf(convertPoint{}),
+			"field X: cannot use string \"a\" as int"},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := convertValue(reflect.ValueOf(tt.value), tt.typ)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+type ConvertBase struct {
+	ID int `json:"id"`
+}
+
+func TestConvertMapToEmbedded(t *testing.T) {
+	type outer struct {
+		*ConvertBase
+		Name string
+	}
+	v, err := convertValue(reflect.ValueOf(map[string]interface{}{"ID": 3, "name": "a"}), reflect.TypeOf(outer{}))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, outer{ConvertBase: &ConvertBase{ID: 3}, Name: "a"}, v.Interface())
+
+	res, err := CallFunc(func(o struct{ *ConvertBase }) int { return o.ID }, map[string]interface{}{"ID": 3})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, res[0].Interface())
+
+	type hidden struct {
+		*convertPoint
+	}
+	_, err = convertValue(reflect.ValueOf(map[string]interface{}{"X": 1}), reflect.TypeOf(hidden{}))
+	assert.EqualError(t, err, "field X: field: convertPoint can not set")
+}
@@ -0,0 +1,97 @@
+package xreflect
+
+import (
+	"reflect"
+)
+
+// deepCopy returns a deep copy of val, including unexported fields. Pointers, maps and slices which are shared
+// in val are shared in the copy as well, so cycles are preserved. The val must not be obtained via unexported
+// fields, use accessibleValue first. Chans, funcs and time.Time values are copied as is.
+func deepCopy(val reflect.Value) reflect.Value {
+	c := &copier{copied: make(map[visitKey]reflect.Value)}
+	return c.copy(val)
+}
+
+type copier struct {
+	copied map[visitKey]reflect.Value
+}
+
+func (c *copier) copy(src reflect.Value) reflect.Value {
+	if !src.IsValid() {
+		return src
+	}
+	typ := src.Type()
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return reflect.Zero(typ)
+		}
+		key := visitKey{ptr: src.Pointer(), typ: typ}
+		if dst, ok := c.copied[key]; ok {
+			return dst
+		}
+		dst := reflect.New(typ.Elem())
+		c.copied[key] = dst
+		dst.Elem().Set(c.copy(src.Elem()))
+		return dst
+	case reflect.Interface:
+		if src.IsNil() {
+			return reflect.Zero(typ)
+		}
+		dst := reflect.New(typ).Elem()
+		dst.Set(c.copy(src.Elem()))
+		return dst
+	case reflect.Struct:
+		if typ == timeType {
+			return src
+		}
+		if !src.CanAddr() {
+			// make the value addressable, so that its unexported fields can be read
+			tmp := reflect.New(typ).Elem()
+			tmp.Set(src)
+			src = tmp
+		}
+		dst := reflect.New(typ).Elem()
+		for i := 0; i < typ.NumField(); i++ {
+			accessibleValue(dst.Field(i)).Set(c.copy(accessibleValue(src.Field(i))))
+		}
+		return dst
+	case reflect.Slice:
+		if src.IsNil() {
+			return reflect.Zero(typ)
+		}
+		key := visitKey{ptr: src.Pointer(), typ: typ, len: src.Len()}
+		if dst, ok := c.copied[key]; ok {
+			return dst
+		}
+		dst := reflect.MakeSlice(typ, src.Len(), src.Cap())
+		c.copied[key] = dst
+		for i := 0; i < src.Len(); i++ {
+			dst.Index(i).Set(c.copy(src.Index(i)))
+		}
+		return dst
+	case reflect.Array:
+		dst := reflect.New(typ).Elem()
+		for i := 0; i < src.Len(); i++ {
+			dst.Index(i).Set(c.copy(src.Index(i)))
+		}
+		return dst
+	case reflect.Map:
+		if src.IsNil() {
+			return reflect.Zero(typ)
+		}
+		key := visitKey{ptr: src.Pointer(), typ: typ}
+		if dst, ok := c.copied[key]; ok {
+			return dst
+		}
+		dst := reflect.MakeMapWithSize(typ, src.Len())
+		c.copied[key] = dst
+		iter := src.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(c.copy(iter.Key()), c.copy(iter.Value()))
+		}
+		return dst
+	default:
+		return src
+	}
+}
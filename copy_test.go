@@ -0,0 +1,65 @@
+package xreflect
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type copyNode struct {
+	Name     string
+	Tags     []string
+	Meta     map[string]interface{}
+	At       time.Time
+	Self     *copyNode
+	children [1]*copyNode
+	secret   *int
+}
+
+func TestDeepCopy(t *testing.T) {
+	secret := 1
+	src := &copyNode{
+		Name:   "a",
+		Tags:   []string{"x"},
+		Meta:   map[string]interface{}{"k": []int{1}},
+		At:     time.Now(),
+		secret: &secret,
+	}
+	src.Self = src
+	src.children[0] = &copyNode{Name: "child"}
+
+	dst := deepCopy(reflect.ValueOf(src)).Interface().(*copyNode)
+	assert.Equal(t, src.Name, dst.Name)
+	assert.Equal(t, src.Tags, dst.Tags)
+	assert.Equal(t, src.Meta, dst.Meta)
+	assert.Equal(t, src.At, dst.At)
+	assert.Equal(t, "child", dst.children[0].Name)
+	assert.Equal(t, 1, *dst.secret)
+	assert.Same(t, dst, dst.Self)
+	assert.NotSame(t, src.children[0], dst.children[0])
+	assert.NotSame(t, src.secret, dst.secret)
+
+	dst.Tags[0] = "y"
+	dst.Meta["k"].([]int)[0] = 2
+	*dst.secret = 2
+	assert.Equal(t, "x", src.Tags[0])
+	assert.Equal(t, 1, src.Meta["k"].([]int)[0])
+	assert.Equal(t, 1, secret)
+
+	v := deepCopy(reflect.ValueOf(*src)).Interface().(copyNode)
+	assert.Equal(t, "a", v.Name)
+	assert.NotSame(t, src, v.Self)
+	assert.Same(t, v.Self, v.Self.Self)
+}
+
+func TestDeepCopySliceCycle(t *testing.T) {
+	src := []interface{}{nil, 1}
+	src[0] = src
+	dst := deepCopy(reflect.ValueOf(src)).Interface().([]interface{})
+	assert.Equal(t, 1, dst[1])
+	inner := dst[0].([]interface{})
+	assert.Same(t, &dst[0], &inner[0])
+	assert.NotSame(t, &src[0], &dst[0])
+}
@@ -0,0 +1,303 @@
+package xreflect
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DumpOptions configures Dump. The zero value renders the whole value as an indented tree without addresses.
+type DumpOptions struct {
+	// Addresses adds the address of non-nil pointers, maps, chans and funcs.
+	Addresses bool
+	// MaxDepth limits the depth of nested structures, slices, arrays and maps, the deeper ones are rendered
+	// as {...} or [...]. Zero means no limit.
+	MaxDepth int
+	// MaxLen limits the number of rendered elements of slices, arrays and maps. Zero means no limit.
+	MaxLen int
+	// Compact renders the value on a single line without types, which is suitable for logs.
+	Compact bool
+}
+
+// Dump renders obj as an indented tree with field names, types and values, for debugging.
+// Unexported fields are included, read through unsafe when possible. A pointer or map which refers back to
+// one of its parents is rendered as <cycle>, and map entries are sorted by key.
+func Dump(obj interface{}, opts DumpOptions) string {
+	if obj == nil {
+		return "nil"
+	}
+	val := reflect.ValueOf(obj)
+	if v, ok := obj.(reflect.Value); ok {
+		val = v
+	}
+	if val.Kind() != reflect.Ptr && val.CanInterface() && !val.CanAddr() {
+		// make the value addressable, so that its unexported fields can be read
+		c := reflect.New(val.Type()).Elem()
+		c.Set(val)
+		val = c
+	}
+
+	d := &dumper{opts: opts, visiting: make(map[visitKey]bool)}
+	d.value(val, 0)
+	return d.buf.String()
+}
+
+type dumper struct {
+	opts     DumpOptions
+	buf      strings.Builder
+	visiting map[visitKey]bool
+}
+
+func (d *dumper) value(val reflect.Value, depth int) {
+	if !val.IsValid() {
+		d.buf.WriteString("nil")
+		return
+	}
+	if val.Kind() == reflect.Interface && !val.IsNil() {
+		val = val.Elem()
+	}
+	if !d.opts.Compact {
+		d.buf.WriteString(val.Type().String())
+		d.buf.WriteByte(' ')
+	}
+	d.body(val, depth)
+}
+
+func (d *dumper) body(val reflect.Value, depth int) {
+	switch val.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			d.buf.WriteString("nil")
+			return
+		}
+		d.address(val)
+		if !d.enter(val) {
+			return
+		}
+		d.body(val.Elem(), depth)
+		d.leave(val)
+	case reflect.Interface:
+		if val.IsNil() {
+			d.buf.WriteString("nil")
+			return
+		}
+		d.value(val.Elem(), depth)
+	case reflect.Struct:
+		d.structBody(val, depth)
+	case reflect.Slice:
+		if val.IsNil() {
+			d.buf.WriteString("nil")
+			return
+		}
+		if !d.enter(val) {
+			return
+		}
+		d.listBody(val, depth)
+		d.leave(val)
+	case reflect.Array:
+		d.listBody(val, depth)
+	case reflect.Map:
+		if val.IsNil() {
+			d.buf.WriteString("nil")
+			return
+		}
+		d.address(val)
+		if !d.enter(val) {
+			return
+		}
+		d.mapBody(val, depth)
+		d.leave(val)
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		if val.IsNil() {
+			d.buf.WriteString("nil")
+			return
+		}
+		d.buf.WriteString(fmt.Sprintf("0x%x", val.Pointer()))
+	default:
+		d.buf.WriteString(scalarString(val))
+	}
+}
+
+// enter marks the pointer, map or slice val as being rendered, or writes a cycle marker if it already is.
+func (d *dumper) enter(val reflect.Value) bool {
+	key := dumpKey(val)
+	if d.visiting[key] {
+		d.buf.WriteString("<cycle>")
+		return false
+	}
+	d.visiting[key] = true
+	return true
+}
+
+func (d *dumper) leave(val reflect.Value) {
+	delete(d.visiting, dumpKey(val))
+}
+
+func dumpKey(val reflect.Value) visitKey {
+	key := visitKey{ptr: val.Pointer(), typ: val.Type()}
+	if val.Kind() == reflect.Slice {
+		key.len = val.Len()
+	}
+	return key
+}
+
+func (d *dumper) address(val reflect.Value) {
+	if d.opts.Addresses {
+		d.buf.WriteString(fmt.Sprintf("0x%x ", val.Pointer()))
+	}
+}
+
+func (d *dumper) structBody(val reflect.Value, depth int) {
+	if val.Type() == timeType {
+		if v := accessibleValue(val); v.CanInterface() {
+			d.buf.WriteString(v.Interface().(time.Time).Format(time.RFC3339Nano))
+			return
+		}
+	}
+	if val.NumField() == 0 {
+		d.buf.WriteString("{}")
+		return
+	}
+	if d.opts.MaxDepth > 0 && depth >= d.opts.MaxDepth {
+		d.buf.WriteString("{...}")
+		return
+	}
+
+	d.buf.WriteByte('{')
+	i := 0
+	_ = RangeFields(val, func(name string, _ reflect.StructField, field reflect.Value) bool {
+		d.separator(i, depth+1)
+		d.buf.WriteString(name)
+		d.buf.WriteString(": ")
+		d.value(accessibleValue(field), depth+1)
+		i++
+		return true
+	})
+	d.closing(depth)
+	d.buf.WriteByte('}')
+}
+
+func (d *dumper) listBody(val reflect.Value, depth int) {
+	if !d.opts.Compact {
+		d.buf.WriteString("len=" + strconv.Itoa(val.Len()) + " ")
+	}
+	if val.Len() == 0 {
+		d.buf.WriteString("[]")
+		return
+	}
+	if d.opts.MaxDepth > 0 && depth >= d.opts.MaxDepth {
+		d.buf.WriteString("[...]")
+		return
+	}
+
+	d.buf.WriteByte('[')
+	n := d.limit(val.Len())
+	for i := 0; i < n; i++ {
+		d.separator(i, depth+1)
+		if !d.opts.Compact {
+			d.buf.WriteString("[" + strconv.Itoa(i) + "]: ")
+		}
+		d.value(accessibleValue(val.Index(i)), depth+1)
+	}
+	d.more(val.Len()-n, depth)
+	d.closing(depth)
+	d.buf.WriteByte(']')
+}
+
+func (d *dumper) mapBody(val reflect.Value, depth int) {
+	if !d.opts.Compact {
+		d.buf.WriteString("len=" + strconv.Itoa(val.Len()) + " ")
+	}
+	if val.Len() == 0 {
+		d.buf.WriteString("{}")
+		return
+	}
+	if d.opts.MaxDepth > 0 && depth >= d.opts.MaxDepth {
+		d.buf.WriteString("{...}")
+		return
+	}
+
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+	entries := make([]entry, 0, val.Len())
+	iter := val.MapRange()
+	for iter.Next() {
+		kd := &dumper{opts: DumpOptions{Compact: true}, visiting: make(map[visitKey]bool)}
+		kd.value(iter.Key(), 0)
+		entries = append(entries, entry{key: kd.buf.String(), value: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	d.buf.WriteByte('{')
+	n := d.limit(len(entries))
+	for i, e := range entries[:n] {
+		d.separator(i, depth+1)
+		d.buf.WriteString(e.key)
+		d.buf.WriteString(": ")
+		d.value(e.value, depth+1)
+	}
+	d.more(len(entries)-n, depth)
+	d.closing(depth)
+	d.buf.WriteByte('}')
+}
+
+func (d *dumper) limit(n int) int {
+	if d.opts.MaxLen > 0 && n > d.opts.MaxLen {
+		return d.opts.MaxLen
+	}
+	return n
+}
+
+func (d *dumper) more(n, depth int) {
+	if n > 0 {
+		d.separator(1, depth+1)
+		d.buf.WriteString(fmt.Sprintf("... %d more", n))
+	}
+}
+
+// separator starts the i-th element of a structure, list or map at the given depth.
+func (d *dumper) separator(i, depth int) {
+	if d.opts.Compact {
+		if i > 0 {
+			d.buf.WriteString(", ")
+		}
+		return
+	}
+	d.buf.WriteByte('\n')
+	d.buf.WriteString(strings.Repeat("  ", depth))
+}
+
+func (d *dumper) closing(depth int) {
+	if !d.opts.Compact {
+		d.buf.WriteByte('\n')
+		d.buf.WriteString(strings.Repeat("  ", depth))
+	}
+}
+
+// scalarString renders a value of a basic kind without calling its Interface method,
+// so that it works for unexported fields as well.
+func scalarString(val reflect.Value) string {
+	switch {
+	case val.Kind() == reflect.String:
+		return strconv.Quote(val.String())
+	case val.Kind() == reflect.Bool:
+		return strconv.FormatBool(val.Bool())
+	case isIntKind(val.Kind()):
+		return strconv.FormatInt(val.Int(), 10)
+	case isUintKind(val.Kind()):
+		return strconv.FormatUint(val.Uint(), 10)
+	case isFloatKind(val.Kind()):
+		return strconv.FormatFloat(val.Float(), 'g', -1, val.Type().Bits())
+	case val.Kind() == reflect.Complex64 || val.Kind() == reflect.Complex128:
+		return strconv.FormatComplex(val.Complex(), 'g', -1, val.Type().Bits())
+	default:
+		return "<" + val.Kind().String() + ">"
+	}
+}
@@ -0,0 +1,91 @@
+package xreflect
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dumpNode struct {
+	Name     string
+	Tags     []string
+	Meta     map[string]int
+	Err      error
+	Next     *dumpNode
+	Parent   *dumpNode
+	At       time.Time
+	secret   float64
+	children [2]*dumpNode
+}
+
+func TestDump(t *testing.T) {
+	assert.Equal(t, "nil", Dump(nil, DumpOptions{}))
+	assert.Equal(t, `int 1`, Dump(1, DumpOptions{}))
+	assert.Equal(t, `"a"`, Dump("a", DumpOptions{Compact: true}))
+
+	root := &dumpNode{
+		Name:   "root",
+		Tags:   []string{"a", "b", "c"},
+		Meta:   map[string]int{"y": 2, "x": 1},
+		Err:    errors.New("boom"),
+		At:     time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		secret: 1.5,
+	}
+	child := &dumpNode{Name: "child", Parent: root}
+	root.Next = child
+	root.children[0] = child
+
+	assert.Equal(t, `*xreflect.dumpNode {
+  Name: string "root"
+  Tags: []string len=3 [
+    [0]: string "a"
+    [1]: string "b"
+    ... 1 more
+  ]
+  Meta: map[string]int len=2 {
+    "x": int 1
+    "y": int 2
+  }
+  Err: *errors.errorString {
+    s: string "boom"
+  }
+  Next: *xreflect.dumpNode {
+    Name: string "child"
+    Tags: []string nil
+    Meta: map[string]int nil
+    Err: error nil
+    Next: *xreflect.dumpNode nil
+    Parent: *xreflect.dumpNode <cycle>
+    At: time.Time 0001-01-01T00:00:00Z
+    secret: float64 0
+    children: [2]*xreflect.dumpNode len=2 [...]
+  }
+  Parent: *xreflect.dumpNode nil
+  At: time.Time 2023-01-02T03:04:05Z
+  secret: float64 1.5
+  children: [2]*xreflect.dumpNode len=2 [
+    [0]: *xreflect.dumpNode {...}
+    [1]: *xreflect.dumpNode nil
+  ]
+}`, Dump(root, DumpOptions{MaxDepth: 2, MaxLen: 2}))
+
+	assert.Equal(t, `{Name: "child", Tags: nil, Meta: nil, Err: nil, Next: nil, Parent: {...}, `+
+		`At: 0001-01-01T00:00:00Z, secret: 0, children: [...]}`, Dump(*child, DumpOptions{Compact: true, MaxDepth: 1}))
+
+	assert.Equal(t, `[1, 2, ... 1 more]`, Dump([]int{1, 2, 3}, DumpOptions{Compact: true, MaxLen: 2}))
+	assert.Equal(t, `{1: true}`, Dump(map[int]bool{1: true}, DumpOptions{Compact: true}))
+
+	m := map[string]interface{}{}
+	m["self"] = m
+	assert.Equal(t, `{"self": <cycle>}`, Dump(m, DumpOptions{Compact: true}))
+	l := []interface{}{nil, 1}
+	l[0] = l
+	assert.Equal(t, `[<cycle>, 1]`, Dump(l, DumpOptions{Compact: true}))
+	assert.Equal(t, `[[1], 1]`, Dump([]interface{}{l[1:], 1}, DumpOptions{Compact: true}))
+
+	s := Dump(child, DumpOptions{Compact: true, Addresses: true, MaxDepth: 1})
+	assert.Regexp(t, regexp.MustCompile(`^0x[0-9a-f]+ \{Name: "child", .* Parent: 0x[0-9a-f]+ \{\.\.\.\}`), s)
+}
@@ -0,0 +1,182 @@
+package xreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ApplyFieldMask copies the fields listed in paths from src to dst, with the semantics of protobuf field masks:
+// a path such as "address.city" selects a nested field, and only the selected fields of dst are replaced, with
+// deep copies of the values in src. The path elements can be Go field names or json tag names. All paths are
+// validated against the type with EmbedStructField and against dst before dst is modified. Nil pointers to
+// intermediate structures, including the embedded pointers of promoted fields, are allocated in dst, or the
+// selected field is cleared if src has a nil pointer.
+// The dst must be a pointer to a structure, and src a structure or pointer to structure of the same type.
+func ApplyFieldMask(dst, src interface{}, paths []string) error {
+	if dst == nil || src == nil {
+		return errors.New("obj must not be nil")
+	}
+	if reflect.TypeOf(dst).Kind() != reflect.Ptr || Type(dst).Kind() != reflect.Struct {
+		return errors.New("dst must be struct pointer")
+	}
+	if Type(src) != Type(dst) {
+		return fmt.Errorf("src must be %s", Type(dst))
+	}
+
+	typ := Type(dst)
+	dstVal, srcVal := Value(dst), Value(src)
+	indexes := make([][]int, len(paths))
+	for i, path := range paths {
+		_, index, err := goFieldPath(typ, path, "json")
+		if err != nil {
+			return err
+		}
+		if _, err := srcVal.FieldByIndexErr(index); err == nil {
+			if err := checkFieldIndex(dstVal, index); err != nil {
+				return err
+			}
+		}
+		indexes[i] = index
+	}
+
+	for _, index := range indexes {
+		applyFieldPath(dstVal, srcVal, index)
+	}
+	return nil
+}
+
+// goFieldPath resolves a field path whose elements are Go names or tagKey tag names, like fieldByTagOrName,
+// into a path of Go names, and validates it with EmbedStructField. Only exported fields can be selected.
+// The index of the field is returned as well, like reflect.StructField.Index, but across nested structures
+// and the pointers to them.
+func goFieldPath(typ reflect.Type, path, tagKey string) (string, []int, error) {
+	if path == "" {
+		return "", nil, errors.New("field path must not be empty")
+	}
+
+	names := strings.Split(path, ".")
+	var index []int
+	target := typ
+	for i, name := range names {
+		if name == "" {
+			return "", nil, fmt.Errorf("field path: %s is invalid", path)
+		}
+		if target.Kind() == reflect.Ptr {
+			target = target.Elem()
+		}
+		if target.Kind() != reflect.Struct {
+			break
+		}
+		field, ok := fieldByTagOrName(target, name, tagKey)
+		if !ok {
+			field, ok = promotedFieldByTag(target, name, tagKey)
+		}
+		if !ok {
+			return "", nil, fmt.Errorf("no such field: %s", name)
+		}
+		if !field.IsExported() {
+			return "", nil, fmt.Errorf("field: %s can not set", field.Name)
+		}
+		names[i] = field.Name
+		index = append(index, field.Index...)
+		target = field.Type
+	}
+
+	goPath := strings.Join(names, ".")
+	if _, err := EmbedStructField(typ, goPath); err != nil {
+		return "", nil, err
+	}
+	return goPath, index, nil
+}
+
+// promotedFieldByTag returns the field promoted from an embedded structure of the struct type typ
+// which has the given tagKey tag name.
+func promotedFieldByTag(typ reflect.Type, name, tagKey string) (reflect.StructField, bool) {
+	for _, field := range reflect.VisibleFields(typ) {
+		if len(field.Index) > 1 && tagName(field, tagKey) == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// applyFieldPath copies the field at index from src to dst, allocating the nil pointers of dst on the way.
+// If a pointer on the way is nil in src, the field is cleared in dst instead.
+func applyFieldPath(dst, src reflect.Value, index []int) {
+	srcField, err := src.FieldByIndexErr(index)
+	if err != nil {
+		if dstField, err := dst.FieldByIndexErr(index); err == nil {
+			dstField.Set(reflect.Zero(dstField.Type()))
+		}
+		return
+	}
+	// checked by checkFieldIndex
+	dstField, _ := fieldByIndex(dst, index, allocPointer)
+	dstField.Set(deepCopy(srcField))
+}
+
+// FieldMaskFromDiff returns the paths of the fields which differ between a and b, which can be passed to
+// ApplyFieldMask to update a with b. Nested structures are compared field by field, unless they have unexported
+// fields, and the paths use json tag names when present, e.g. "address.city".
+// The a and b must be structures or pointers to structures of the same type.
+func FieldMaskFromDiff(a, b interface{}) ([]string, error) {
+	if a == nil || b == nil {
+		return nil, errors.New("obj must not be nil")
+	}
+	if Type(a).Kind() != reflect.Struct {
+		return nil, errors.New("obj must be struct")
+	}
+	if Type(a) != Type(b) {
+		return nil, fmt.Errorf("b must be %s", Type(a))
+	}
+
+	var paths []string
+	diffFields(Value(a), Value(b), "", &paths)
+	return paths, nil
+}
+
+func diffFields(a, b reflect.Value, prefix string, paths *[]string) {
+	typ := a.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := tagName(field, "json")
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		fa, fb := a.Field(i), b.Field(i)
+		if fa.Kind() == reflect.Ptr && !fa.IsNil() && !fb.IsNil() && isPlainStruct(fa.Type().Elem()) {
+			diffFields(fa.Elem(), fb.Elem(), path, paths)
+			continue
+		}
+		if isPlainStruct(fa.Type()) {
+			diffFields(fa, fb, path, paths)
+			continue
+		}
+		if !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+			*paths = append(*paths, path)
+		}
+	}
+}
+
+// isPlainStruct reports whether typ is a structure type whose fields are all exported.
+func isPlainStruct(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if !typ.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
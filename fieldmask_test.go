@@ -0,0 +1,125 @@
+package xreflect
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type maskAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type maskUser struct {
+	Name     string       `json:"name"`
+	Age      int          `json:"age"`
+	Tags     []string     `json:"tags"`
+	Address  maskAddress  `json:"address"`
+	Billing  *maskAddress `json:"billing"`
+	Created  time.Time    `json:"created"`
+	Internal string       `json:"-"`
+	secret   string
+}
+
+func TestApplyFieldMask(t *testing.T) {
+	dst := &maskUser{Name: "old", Age: 1, Address: maskAddress{City: "a", Zip: "1"}, secret: "s"}
+	src := maskUser{
+		Name:    "new",
+		Age:     2,
+		Tags:    []string{"x"},
+		Address: maskAddress{City: "b", Zip: "2"},
+		Billing: &maskAddress{City: "c", Zip: "3"},
+	}
+
+	assert.EqualError(t, ApplyFieldMask(nil, src, nil), "obj must not be nil")
+	assert.EqualError(t, ApplyFieldMask(*dst, src, nil), "dst must be struct pointer")
+	assert.EqualError(t, ApplyFieldMask(dst, maskAddress{}, nil), "src must be xreflect.maskUser")
+	assert.EqualError(t, ApplyFieldMask(dst, src, []string{"name", ""}), "field path must not be empty")
+	assert.EqualError(t, ApplyFieldMask(dst, src, []string{"name", "address..city"}), "field path: address..city is invalid")
+	assert.EqualError(t, ApplyFieldMask(dst, src, []string{"name", "nope"}), "no such field: nope")
+	assert.EqualError(t, ApplyFieldMask(dst, src, []string{"tags.x"}), "field: Tags is not struct")
+	assert.EqualError(t, ApplyFieldMask(dst, src, []string{"secret"}), "field: secret can not set")
+	assert.Equal(t, "old", dst.Name)
+
+	assert.Equal(t, nil, ApplyFieldMask(dst, src, []string{"name", "tags", "address.city", "Billing.Zip"}))
+	assert.Equal(t, "new", dst.Name)
+	assert.Equal(t, 1, dst.Age)
+	assert.Equal(t, []string{"x"}, dst.Tags)
+	assert.Equal(t, maskAddress{City: "b", Zip: "1"}, dst.Address)
+	assert.Equal(t, &maskAddress{Zip: "3"}, dst.Billing)
+	assert.Equal(t, "s", dst.secret)
+
+	src.Tags[0] = "y"
+	assert.Equal(t, "x", dst.Tags[0])
+
+	src.Billing = nil
+	assert.Equal(t, nil, ApplyFieldMask(dst, &src, []string{"billing.zip"}))
+	assert.Equal(t, &maskAddress{}, dst.Billing)
+	assert.Equal(t, nil, ApplyFieldMask(dst, &src, []string{"billing"}))
+	assert.Nil(t, dst.Billing)
+}
+
+func TestFieldMaskFromDiff(t *testing.T) {
+	_, err := FieldMaskFromDiff(nil, nil)
+	assert.EqualError(t, err, "obj must not be nil")
+	_, err = FieldMaskFromDiff(1, 1)
+	assert.EqualError(t, err, "obj must be struct")
+	_, err = FieldMaskFromDiff(maskUser{}, maskAddress{})
+	assert.EqualError(t, err, "b must be xreflect.maskUser")
+
+	a := maskUser{Name: "a", Tags: []string{"x"}, Address: maskAddress{City: "a"}, Billing: &maskAddress{Zip: "1"}}
+	b := a
+	b.Tags = []string{"x"}
+	b.Billing = &maskAddress{Zip: "1"}
+	paths, err := FieldMaskFromDiff(&a, &b)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(paths))
+
+	b.Name = "b"
+	b.Address.Zip = "2"
+	b.Billing = &maskAddress{Zip: "2"}
+	b.Created = time.Now()
+	b.Internal = "i"
+	b.secret = "s"
+	paths, err = FieldMaskFromDiff(a, b)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"name", "address.zip", "billing.zip", "created", "Internal"}, paths)
+
+	b.Billing = nil
+	paths, _ = FieldMaskFromDiff(a, b)
+	assert.Equal(t, []string{"name", "address.zip", "billing", "created", "Internal"}, paths)
+
+	assert.Equal(t, nil, ApplyFieldMask(&a, b, paths))
+	paths, _ = FieldMaskFromDiff(a, b)
+	assert.Equal(t, 0, len(paths))
+}
+
+type MaskBase struct {
+	ID   int          `json:"id"`
+	Home *maskAddress `json:"home"`
+}
+
+type maskAccount struct {
+	*MaskBase
+	Name string `json:"name"`
+}
+
+func TestApplyFieldMaskEmbedded(t *testing.T) {
+	dst := &maskAccount{}
+	src := maskAccount{MaskBase: &MaskBase{ID: 1, Home: &maskAddress{City: "a"}}, Name: "n"}
+	assert.Equal(t, nil, ApplyFieldMask(dst, src, []string{"id", "home.city"}))
+	assert.Equal(t, &MaskBase{ID: 1, Home: &maskAddress{City: "a"}}, dst.MaskBase)
+	assert.Equal(t, "", dst.Name)
+
+	src.MaskBase = nil
+	assert.Equal(t, nil, ApplyFieldMask(dst, src, []string{"home.city"}))
+	assert.Equal(t, &MaskBase{ID: 1, Home: &maskAddress{}}, dst.MaskBase)
+	assert.Equal(t, nil, ApplyFieldMask(dst, src, []string{"ID", "home"}))
+	assert.Equal(t, &MaskBase{}, dst.MaskBase)
+
+	dst.MaskBase = nil
+	assert.Equal(t, nil, ApplyFieldMask(dst, src, []string{"id", "home.zip"}))
+	assert.Nil(t, dst.MaskBase)
+}
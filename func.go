@@ -11,62 +11,117 @@ import (
 // Variadic arguments need to be flattened, otherwise should use CallFuncSlice.
 // There is no need to parse errors from the returned []reflect.Value. If the called function's last return value
 // is an error, it will be extracted and returned as the last return value of CallFunc.
+// Each argument is checked against its parameter type and converted if needed, e.g. an int is accepted for an int64
+// parameter and a numeric string for an int parameter, but pointers are never taken or dereferenced.
+// If the argument is nil, the zero value of the parameter type is used.
 func CallFunc(fn interface{}, args ...interface{}) ([]reflect.Value, error) {
-	if fn == nil {
-		return nil, errors.New("fn must not be nil")
+	retValues, err := callFunc(fn, args, false)
+	if err != nil {
+		return nil, err
 	}
 
-	typ := Type(fn)
-	val := Value(fn)
-	if !isSupportedKind(val.Kind(), []reflect.Kind{reflect.Func}) {
-		return nil, errors.New("fn must be func")
+	return extractError(retValues)
+}
+
+// CallFuncSlice has the same functionality as CallFunc, it must have variadic parameters, but it uses
+// the CallSlice method of reflect.Value under the hood.
+// CallSlice calls the variadic function v with the input arguments in,
+// assigning the slice in[len(in)-1] to v's final variadic argument.
+// For example, if len(in) == 3, v.CallSlice(in) represents the Go call v(in[0], in[1], in[2]...).
+// Arguments are converted in the same way as CallFunc.
+func CallFuncSlice(fn interface{}, args ...interface{}) ([]reflect.Value, error) {
+	retValues, err := callFunc(fn, args, true)
+	if err != nil {
+		return nil, err
 	}
-	if !typ.IsVariadic() && len(args) != typ.NumIn() {
-		return nil, fmt.Errorf("fn params num is %d, but got %d", typ.NumIn(), len(args))
+
+	return extractError(retValues)
+}
+
+// CallMethod calls the method `method` of the `obj` object and returns the result, supporting variadic parameters.
+// The `obj` object can be a value or a pointer of any named type, such as a struct, `type Celsius float64` or
+// `type Set map[string]struct{}`. If the method has a pointer receiver, `obj` must be a pointer, otherwise
+// an error explaining the receiver mismatch is returned.
+// It internally uses CallFunc, see CallFunc for more details.
+func CallMethod(obj interface{}, method string, params ...interface{}) ([]reflect.Value, error) {
+	methodValue, err := methodByName(obj, method)
+	if err != nil {
+		return nil, err
 	}
-	if typ.IsVariadic() {
-		if len(args) < typ.NumIn()-1 {
-			return nil, fmt.Errorf("fn params num is %d at least, but got %d", typ.NumIn()-1, len(args))
+
+	return CallFunc(methodValue.Interface(), params...)
+}
+
+// CallMethodSlice has the same functionality as CallMethod, and it uses CallFuncSlice as its underlying implementation.
+// For more details, refer to the CallFuncSlice documentation.
+func CallMethodSlice(obj interface{}, method string, params ...interface{}) ([]reflect.Value, error) {
+	methodValue, err := methodByName(obj, method)
+	if err != nil {
+		return nil, err
+	}
+
+	return CallFuncSlice(methodValue.Interface(), params...)
+}
+
+func methodByName(obj interface{}, method string) (reflect.Value, error) {
+	var empty reflect.Value
+	if obj == nil {
+		return empty, errors.New("obj must not be nil")
+	}
+
+	val := reflect.ValueOf(obj)
+	methodValue := val.MethodByName(method)
+	if methodValue.IsValid() {
+		return methodValue, nil
+	}
+
+	// The method may be declared on a pointer receiver, calling it on a copy would silently
+	// drop its side effects, so report the mismatch instead.
+	if val.Kind() != reflect.Pointer && val.Kind() != reflect.Interface {
+		if _, ok := reflect.PointerTo(val.Type()).MethodByName(method); ok {
+			return empty, fmt.Errorf("method: %s has pointer receiver, obj must be *%s", method, val.Type())
 		}
 	}
+	return empty, fmt.Errorf("method: %s not found", method)
+}
 
+// convertArgs converts args to the parameter types of the func type typ.
+// If slice is true, the last argument is used as the whole variadic slice, as with reflect.Value.CallSlice.
+func convertArgs(typ reflect.Type, args []interface{}, slice bool) ([]reflect.Value, error) {
 	reflectArgs := make([]reflect.Value, len(args))
 	for i, arg := range args {
-		// If the argument is nil, use zero value
-		if arg == nil {
+		var in reflect.Type
+		if typ.IsVariadic() && !slice && i >= typ.NumIn()-1 {
 			// Handling variadic parameters, whose type is a slice.
-			// We need to create the zero value of its element type here.
-			if typ.IsVariadic() && i >= typ.NumIn()-1 {
-				reflectArgs[i] = reflect.New(typ.In(typ.NumIn() - 1).Elem()).Elem()
-			} else {
-				reflectArgs[i] = reflect.New(typ.In(i)).Elem()
-			}
+			// The argument should be converted to its element type here.
+			in = typ.In(typ.NumIn() - 1).Elem()
 		} else {
-			reflectArgs[i] = reflect.ValueOf(arg)
+			in = typ.In(i)
 		}
-	}
 
-	var retValues []reflect.Value
-	retValues = val.Call(reflectArgs)
-
-	if len(retValues) > 0 {
-		// If the last return value of the function is an error and not empty, extract it
-		if errResult := retValues[len(retValues)-1].Interface(); errResult != nil {
-			if err, ok := errResult.(error); ok {
-				return retValues[0 : len(retValues)-1], err
-			}
+		argValue, err := convertArg(reflect.ValueOf(arg), in)
+		if err != nil {
+			return nil, fmt.Errorf("fn param %d: %w", i, err)
 		}
+		reflectArgs[i] = argValue
 	}
+	return reflectArgs, nil
+}
 
-	return retValues, nil
+// callFunc validates and converts args, then calls fn and returns all of its return values.
+// If slice is true, fn is called by reflect.Value.CallSlice.
+func callFunc(fn interface{}, args []interface{}, slice bool) ([]reflect.Value, error) {
+	call, err := prepareCall(fn, args, slice)
+	if err != nil {
+		return nil, err
+	}
+
+	return call(), nil
 }
 
-// CallFuncSlice has the same functionality as CallFunc, it must have variadic parameters, but it uses
-// the CallSlice method of reflect.Value under the hood.
-// CallSlice calls the variadic function v with the input arguments in,
-// assigning the slice in[len(in)-1] to v's final variadic argument.
-// For example, if len(in) == 3, v.CallSlice(in) represents the Go call v(in[0], in[1], in[2]...).
-func CallFuncSlice(fn interface{}, args ...interface{}) ([]reflect.Value, error) {
+// prepareCall validates and converts args, and returns a function which calls fn with them.
+// If slice is true, fn is called by reflect.Value.CallSlice.
+func prepareCall(fn interface{}, args []interface{}, slice bool) (func() []reflect.Value, error) {
 	if fn == nil {
 		return nil, errors.New("fn must not be nil")
 	}
@@ -76,28 +131,40 @@ func CallFuncSlice(fn interface{}, args ...interface{}) ([]reflect.Value, error)
 	if !isSupportedKind(val.Kind(), []reflect.Kind{reflect.Func}) {
 		return nil, errors.New("fn must be func")
 	}
-	if !typ.IsVariadic() {
-		return nil, errors.New("fn must be variadic")
+	if slice {
+		if !typ.IsVariadic() {
+			return nil, errors.New("fn must be variadic")
+		}
+		if len(args) != typ.NumIn() {
+			return nil, fmt.Errorf("use reflect.CallSlice, fn params num should be %d, but got %d",
+				typ.NumIn(), len(args))
+		}
+	} else {
+		if !typ.IsVariadic() && len(args) != typ.NumIn() {
+			return nil, fmt.Errorf("fn params num is %d, but got %d", typ.NumIn(), len(args))
+		}
+		if typ.IsVariadic() && len(args) < typ.NumIn()-1 {
+			return nil, fmt.Errorf("fn params num is %d at least, but got %d", typ.NumIn()-1, len(args))
+		}
 	}
 
-	if len(args) != typ.NumIn() {
-		return nil, fmt.Errorf("use reflect.CallSlice, fn params num should be %d, but got %d",
-			typ.NumIn(), len(args))
+	reflectArgs, err := convertArgs(typ, args, slice)
+	if err != nil {
+		return nil, err
 	}
 
-	reflectArgs := make([]reflect.Value, len(args))
-	for i, arg := range args {
-		// If the argument is nil, use zero value
-		if arg == nil {
-			reflectArgs[i] = reflect.New(typ.In(i)).Elem()
-		} else {
-			reflectArgs[i] = reflect.ValueOf(arg)
-		}
+	if slice {
+		return func() []reflect.Value {
+			return val.CallSlice(reflectArgs)
+		}, nil
 	}
+	return func() []reflect.Value {
+		return val.Call(reflectArgs)
+	}, nil
+}
 
-	var retValues []reflect.Value
-	retValues = val.CallSlice(reflectArgs)
-
+// extractError extracts the last return value if it is a non-nil error.
+func extractError(retValues []reflect.Value) ([]reflect.Value, error) {
 	if len(retValues) > 0 {
 		// If the last return value of the function is an error and not empty, extract it.
 		if errResult := retValues[len(retValues)-1].Interface(); errResult != nil {
@@ -110,45 +177,7 @@ func CallFuncSlice(fn interface{}, args ...interface{}) ([]reflect.Value, error)
 	return retValues, nil
 }
 
-// CallMethod calls the method `method` of the `obj` object and returns the result, supporting variadic parameters.
-// The `obj` object must be a struct or a struct pointer.
-// It internally uses CallFunc, see CallFunc for more details.
-func CallMethod(obj interface{}, method string, params ...interface{}) ([]reflect.Value, error) {
-	if obj == nil {
-		return nil, errors.New("obj must not be nil")
-	}
-
-	typ := Type(obj)
-	if !isSupportedKind(typ.Kind(), []reflect.Kind{reflect.Struct}) {
-		return nil, errors.New("obj must be struct or struct pointer")
-	}
-
-	val := reflect.ValueOf(obj)
-	methodValue := val.MethodByName(method)
-	if !methodValue.IsValid() {
-		return nil, fmt.Errorf("method: %s not found", method)
-	}
-
-	return CallFunc(methodValue.Interface(), params...)
-}
-
-// CallMethodSlice has the same functionality as CallMethod, and it uses CallFuncSlice as its underlying implementation.
-// For more details, refer to the CallFuncSlice documentation.
-func CallMethodSlice(obj interface{}, method string, params ...interface{}) ([]reflect.Value, error) {
-	if obj == nil {
-		return nil, errors.New("obj must not be nil")
-	}
-
-	typ := Type(obj)
-	if !isSupportedKind(typ.Kind(), []reflect.Kind{reflect.Struct}) {
-		return nil, errors.New("obj must be struct or struct pointer")
-	}
-
-	val := reflect.ValueOf(obj)
-	methodValue := val.MethodByName(method)
-	if !methodValue.IsValid() {
-		return nil, fmt.Errorf("method: %s not found", method)
-	}
-
-	return CallFuncSlice(methodValue.Interface(), params...)
+// returnsError reports whether the last return value of the func type typ is an error.
+func returnsError(typ reflect.Type) bool {
+	return typ.NumOut() > 0 && typ.Out(typ.NumOut()-1) == errorType
 }
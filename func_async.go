@@ -0,0 +1,108 @@
+package xreflect
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Future is the pending result of a call started by CallFuncAsync or CallMethodAsync.
+type Future struct {
+	done chan struct{}
+	res  []reflect.Value
+	err  error
+}
+
+// CallFuncAsync calls fn with args in a new goroutine, in the same way as SafeCallFunc,
+// and returns a Future of its result.
+func CallFuncAsync(fn interface{}, args ...interface{}) *Future {
+	return newFuture(func() ([]reflect.Value, error) {
+		return SafeCallFunc(fn, args...)
+	})
+}
+
+// CallMethodAsync calls the method `method` of obj in a new goroutine, in the same way as SafeCallMethod,
+// and returns a Future of its result.
+func CallMethodAsync(obj interface{}, method string, params ...interface{}) *Future {
+	return newFuture(func() ([]reflect.Value, error) {
+		return SafeCallMethod(obj, method, params...)
+	})
+}
+
+func newFuture(call func() ([]reflect.Value, error)) *Future {
+	f := &Future{done: make(chan struct{})}
+	go func() {
+		defer close(f.done)
+		f.res, f.err = call()
+	}()
+	return f
+}
+
+// Done returns a channel which is closed when the call returns.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits for the call to return, and returns its result in the same way as CallFunc.
+// A panic of the called function is returned as a *CallPanicError.
+func (f *Future) Wait() ([]reflect.Value, error) {
+	<-f.done
+	return f.res, f.err
+}
+
+// WaitContext has the same functionality as Wait, but returns ctx.Err() if ctx is done before the call returns.
+func (f *Future) WaitContext(ctx context.Context) ([]reflect.Value, error) {
+	select {
+	case <-f.done:
+		return f.res, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Call describes a function call, or a method call if Obj is not nil, for CallAll.
+type Call struct {
+	Fn     interface{}
+	Obj    interface{}
+	Method string
+	Args   []interface{}
+}
+
+// CallOutcome is the result of a Call.
+type CallOutcome struct {
+	Values []reflect.Value
+	Err    error
+}
+
+// CallAll runs calls concurrently with at most workers goroutines, or one goroutine per call if workers is
+// not positive, and returns their results in the same order as calls. Each call is run in the same way as
+// SafeCallFunc or SafeCallMethod, so a panic is recovered and returned as a *CallPanicError in its CallOutcome.
+func CallAll(calls []Call, workers int) []CallOutcome {
+	res := make([]CallOutcome, len(calls))
+	if workers <= 0 || workers > len(calls) {
+		workers = len(calls)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				c := calls[i]
+				if c.Obj != nil {
+					res[i].Values, res[i].Err = SafeCallMethod(c.Obj, c.Method, c.Args...)
+				} else {
+					res[i].Values, res[i].Err = SafeCallFunc(c.Fn, c.Args...)
+				}
+			}
+		}()
+	}
+	for i := range calls {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return res
+}
@@ -0,0 +1,82 @@
+package xreflect
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallFuncAsync(t *testing.T) {
+	f := CallFuncAsync(addFunc, 1, 2)
+	<-f.Done()
+	res, err := f.Wait()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, res[0].Interface())
+
+	_, err = CallFuncAsync(addFunc, 1).Wait()
+	assert.EqualError(t, err, "fn params num is 2, but got 1")
+
+	_, err = CallFuncAsync(panicFunc, "boom").Wait()
+	var panicErr *CallPanicError
+	assert.Equal(t, true, errors.As(err, &panicErr))
+
+	res, err = CallMethodAsync(&A{1}, "AddOne").Wait()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, res[0].Interface())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	f = CallFuncAsync(sleepFunc, 100*time.Millisecond)
+	_, err = f.WaitContext(ctx)
+	assert.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+	res, err = f.WaitContext(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "done", res[0].Interface())
+}
+
+func TestCallAll(t *testing.T) {
+	assert.Equal(t, 0, len(CallAll(nil, 2)))
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	track := func(d time.Duration, n int) int {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(d)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return n
+	}
+
+	calls := []Call{
+		{Fn: track, Args: []interface{}{20 * time.Millisecond, 0}},
+		{Fn: track, Args: []interface{}{time.Millisecond, 1}},
+		{Fn: panicFunc, Args: []interface{}{"boom"}},
+		{Obj: &A{1}, Method: "AddInts", Args: []interface{}{1, 2}},
+		{Fn: track, Args: []interface{}{time.Millisecond, 4}},
+		{Obj: A{}, Method: "AddOne"},
+		{},
+	}
+	res := CallAll(calls, 2)
+	assert.Equal(t, len(calls), len(res))
+	assert.Equal(t, 0, res[0].Values[0].Interface())
+	assert.Equal(t, 1, res[1].Values[0].Interface())
+	assert.EqualError(t, res[2].Err, "call github.com/morrisxyang/xreflect.panicFunc panic: boom")
+	assert.Equal(t, 4, res[3].Values[0].Interface())
+	assert.Equal(t, 4, res[4].Values[0].Interface())
+	assert.EqualError(t, res[5].Err, "method: AddOne has pointer receiver, obj must be *xreflect.A")
+	assert.EqualError(t, res[6].Err, "fn must not be nil")
+	assert.Equal(t, true, maxRunning <= 2)
+
+	res = CallAll(calls[:2], 0)
+	assert.Equal(t, 1, res[1].Values[0].Interface())
+}
@@ -0,0 +1,234 @@
+package xreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type methodKey struct {
+	typ    reflect.Type
+	method string
+}
+
+var (
+	paramNamesMu     sync.RWMutex
+	funcParamNames   = make(map[uintptr][]string)
+	methodParamNames = make(map[methodKey][]string)
+)
+
+// RegisterFuncParams registers the parameter names of fn, which are used by CallFuncWithMap to bind
+// the map values to the parameters. The number of names must equal the number of parameters of fn.
+// Since Go does not keep parameter names at runtime, fn is identified by its code pointer, so all closures
+// created by the same function literal share the registered names. Funcs created by reflect, such as those
+// returned by Wrap and method values obtained by reflect, all share the same code pointer and are rejected.
+func RegisterFuncParams(fn interface{}, names ...string) error {
+	if fn == nil {
+		return errors.New("fn must not be nil")
+	}
+	val := reflect.ValueOf(fn)
+	if val.Kind() != reflect.Func {
+		return errors.New("fn must be func")
+	}
+	if isReflectFunc(val) {
+		return errors.New("fn must not be created by reflect, its code pointer is shared with other funcs")
+	}
+	if err := checkParamNames(val.Type(), 0, names); err != nil {
+		return err
+	}
+
+	paramNamesMu.Lock()
+	defer paramNamesMu.Unlock()
+	funcParamNames[val.Pointer()] = names
+	return nil
+}
+
+// RegisterMethodParams registers the parameter names of the method `method` of obj, which are used by
+// CallMethodWithMap. The names are shared by the type of obj and the pointer to it.
+func RegisterMethodParams(obj interface{}, method string, names ...string) error {
+	if obj == nil {
+		return errors.New("obj must not be nil")
+	}
+	m, err := Method(obj, method)
+	if err != nil {
+		return err
+	}
+	if err := checkParamNames(m.Method.Type, 1, names); err != nil {
+		return err
+	}
+
+	paramNamesMu.Lock()
+	defer paramNamesMu.Unlock()
+	methodParamNames[methodKey{Type(obj), method}] = names
+	return nil
+}
+
+// CallFuncWithMap calls fn with the values of params bound by the parameter names registered by
+// RegisterFuncParams. Keys are matched exactly, or case-insensitively, to the names, and values are converted
+// to the parameter types in the same way as CallFunc. A missing key is passed as the zero value of the
+// parameter, the value of a variadic parameter must be a slice. Unknown keys are rejected.
+func CallFuncWithMap(fn interface{}, params map[string]interface{}) ([]reflect.Value, error) {
+	if fn == nil {
+		return nil, errors.New("fn must not be nil")
+	}
+	val := reflect.ValueOf(fn)
+	if val.Kind() != reflect.Func {
+		return nil, errors.New("fn must be func")
+	}
+
+	paramNamesMu.RLock()
+	names, ok := funcParamNames[val.Pointer()]
+	paramNamesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("param names of fn: %s are not registered", funcName(val))
+	}
+
+	return callFuncWithMap(val, names, params)
+}
+
+// CallFuncWithStruct calls fn with the exported fields of the struct params, bound positionally in declaration
+// order. The number of exported fields must equal the number of parameters of fn, the field for a variadic
+// parameter must be a slice. Values are converted to the parameter types in the same way as CallFunc.
+// The params can either be a structure or pointer to structure.
+func CallFuncWithStruct(fn interface{}, params interface{}) ([]reflect.Value, error) {
+	if fn == nil {
+		return nil, errors.New("fn must not be nil")
+	}
+	val := reflect.ValueOf(fn)
+	if val.Kind() != reflect.Func {
+		return nil, errors.New("fn must be func")
+	}
+
+	return callFuncWithStruct(val, params)
+}
+
+// CallMethodWithMap has the same functionality as CallFuncWithMap for the method `method` of obj,
+// whose parameter names are registered by RegisterMethodParams.
+func CallMethodWithMap(obj interface{}, method string, params map[string]interface{}) ([]reflect.Value, error) {
+	methodValue, err := methodByName(obj, method)
+	if err != nil {
+		return nil, err
+	}
+
+	paramNamesMu.RLock()
+	names, ok := methodParamNames[methodKey{Type(obj), method}]
+	paramNamesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("param names of method: %s are not registered", method)
+	}
+
+	return callFuncWithMap(methodValue, names, params)
+}
+
+// CallMethodWithStruct has the same functionality as CallFuncWithStruct for the method `method` of obj.
+func CallMethodWithStruct(obj interface{}, method string, params interface{}) ([]reflect.Value, error) {
+	methodValue, err := methodByName(obj, method)
+	if err != nil {
+		return nil, err
+	}
+
+	return callFuncWithStruct(methodValue, params)
+}
+
+func callFuncWithMap(fn reflect.Value, names []string, params map[string]interface{}) ([]reflect.Value, error) {
+	typ := fn.Type()
+	values := make([]reflect.Value, len(names))
+	found := make([]bool, len(names))
+	var unknown []string
+	for key, value := range params {
+		i := paramIndex(names, key)
+		if i < 0 {
+			unknown = append(unknown, key)
+			continue
+		}
+		v, err := convertArg(reflect.ValueOf(value), typ.In(i))
+		if err != nil {
+			return nil, fmt.Errorf("fn param %s: %w", names[i], err)
+		}
+		values[i], found[i] = v, true
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown params: %s", strings.Join(unknown, ", "))
+	}
+
+	args := make([]interface{}, len(names))
+	for i := range names {
+		if !found[i] {
+			values[i] = reflect.Zero(typ.In(i))
+		}
+		args[i] = values[i].Interface()
+	}
+	return callValueFunc(fn, args, typ.IsVariadic())
+}
+
+func callFuncWithStruct(fn reflect.Value, params interface{}) ([]reflect.Value, error) {
+	if params == nil {
+		return nil, errors.New("params must not be nil")
+	}
+	val := Value(params)
+	if !isSupportedKind(val.Kind(), []reflect.Kind{reflect.Struct}) {
+		return nil, errors.New("params must be struct or struct pointer")
+	}
+
+	var args []interface{}
+	for i := 0; i < val.NumField(); i++ {
+		if val.Type().Field(i).IsExported() {
+			args = append(args, val.Field(i).Interface())
+		}
+	}
+	if len(args) != fn.Type().NumIn() {
+		return nil, fmt.Errorf("fn params num is %d, but params has %d exported fields",
+			fn.Type().NumIn(), len(args))
+	}
+	return callValueFunc(fn, args, fn.Type().IsVariadic())
+}
+
+// callValueFunc calls the func value fn, using CallFuncSlice semantics if slice is true.
+func callValueFunc(fn reflect.Value, args []interface{}, slice bool) ([]reflect.Value, error) {
+	retValues, err := callFunc(fn, args, slice)
+	if err != nil {
+		return nil, err
+	}
+	return extractError(retValues)
+}
+
+// isReflectFunc reports whether the code pointer of fn belongs to reflect, which is the case of all funcs
+// created by reflect.MakeFunc and of method values obtained by reflect.
+func isReflectFunc(fn reflect.Value) bool {
+	return strings.HasPrefix(funcName(fn), "reflect.")
+}
+
+func checkParamNames(typ reflect.Type, skip int, names []string) error {
+	if len(names) != typ.NumIn()-skip {
+		return fmt.Errorf("fn params num is %d, but got %d names", typ.NumIn()-skip, len(names))
+	}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if name == "" {
+			return errors.New("param name must not be empty")
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate param name: %s", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+func paramIndex(names []string, key string) int {
+	for i, name := range names {
+		if name == key {
+			return i
+		}
+	}
+	for i, name := range names {
+		if strings.EqualFold(name, key) {
+			return i
+		}
+	}
+	return -1
+}
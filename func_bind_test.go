@@ -0,0 +1,157 @@
+package xreflect
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func greetFunc(name string, times int, suffixes ...string) string {
+	s := ""
+	for i := 0; i < times; i++ {
+		s += "hi " + name
+	}
+	for _, suffix := range suffixes {
+		s += suffix
+	}
+	return s
+}
+
+var scaleFunc = func(p *Town, n int64) (int, error) {
+	if p == nil {
+		return 0, fmt.Errorf("nil town")
+	}
+	return p.Int * int(n), nil
+}
+
+type Greeter struct {
+	Greeting string
+}
+
+func (g *Greeter) Greet(name string, loud bool) string {
+	if loud {
+		return g.Greeting + " " + name + "!"
+	}
+	return g.Greeting + " " + name
+}
+
+func TestCallFuncWithMap(t *testing.T) {
+	err := RegisterFuncParams(nil)
+	assert.EqualError(t, err, "fn must not be nil")
+	err = RegisterFuncParams(1)
+	assert.EqualError(t, err, "fn must be func")
+	err = RegisterFuncParams(greetFunc, "name")
+	assert.EqualError(t, err, "fn params num is 3, but got 1 names")
+	err = RegisterFuncParams(greetFunc, "name", "name", "suffixes")
+	assert.EqualError(t, err, "duplicate param name: name")
+	err = RegisterFuncParams(greetFunc, "name", "", "suffixes")
+	assert.EqualError(t, err, "param name must not be empty")
+
+	made := reflect.MakeFunc(reflect.TypeOf(greetFunc), func(args []reflect.Value) []reflect.Value {
+		return reflect.ValueOf(greetFunc).Call(args)
+	}).Interface()
+	err = RegisterFuncParams(made, "name", "times", "suffixes")
+	assert.EqualError(t, err, "fn must not be created by reflect, its code pointer is shared with other funcs")
+	err = RegisterFuncParams(Value(&Greeter{}).Addr().MethodByName("Greet").Interface(), "name", "loud")
+	assert.EqualError(t, err, "fn must not be created by reflect, its code pointer is shared with other funcs")
+
+	_, err = CallFuncWithMap(panicFunc, nil)
+	assert.EqualError(t, err, "param names of fn: github.com/morrisxyang/xreflect.panicFunc are not registered")
+
+	err = RegisterFuncParams(greetFunc, "name", "times", "suffixes")
+	assert.Equal(t, nil, err)
+
+	res, err := CallFuncWithMap(greetFunc, map[string]interface{}{
+		"name":     "bob",
+		"Times":    float64(2),
+		"suffixes": []interface{}{"!", "?"},
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hi bobhi bob!?", res[0].Interface())
+
+	res, err = CallFuncWithMap(greetFunc, map[string]interface{}{"name": "bob"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "", res[0].Interface())
+
+	_, err = CallFuncWithMap(greetFunc, map[string]interface{}{"name": "bob", "time": 1, "a": 1})
+	assert.EqualError(t, err, "unknown params: a, time")
+
+	_, err = CallFuncWithMap(greetFunc, map[string]interface{}{"times": "x"})
+	assert.EqualError(t, err, "fn param times: cannot use string \"x\" as int")
+
+	err = RegisterFuncParams(scaleFunc, "town", "n")
+	assert.Equal(t, nil, err)
+	res, err = CallFuncWithMap(scaleFunc, map[string]interface{}{
+		"town": map[string]interface{}{"Int": 3},
+		"n":    "2",
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 6, res[0].Interface())
+
+	_, err = CallFuncWithMap(scaleFunc, map[string]interface{}{"n": 2})
+	assert.EqualError(t, err, "nil town")
+}
+
+func TestCallFuncWithStruct(t *testing.T) {
+	_, err := CallFuncWithStruct(nil, nil)
+	assert.EqualError(t, err, "fn must not be nil")
+
+	_, err = CallFuncWithStruct(greetFunc, nil)
+	assert.EqualError(t, err, "params must not be nil")
+
+	_, err = CallFuncWithStruct(greetFunc, "")
+	assert.EqualError(t, err, "params must be struct or struct pointer")
+
+	type greetParams struct {
+		Name     string
+		Times    int
+		internal int
+		Suffixes []string
+	}
+	res, err := CallFuncWithStruct(greetFunc, &greetParams{Name: "bob", Times: 1, Suffixes: []string{"!"}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hi bob!", res[0].Interface())
+
+	type scaleParams struct {
+		Town *Town
+		N    int
+	}
+	res, err = CallFuncWithStruct(scaleFunc, scaleParams{Town: &Town{Int: 2}, N: 3})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 6, res[0].Interface())
+
+	_, err = CallFuncWithStruct(scaleFunc, greetParams{})
+	assert.EqualError(t, err, "fn params num is 2, but params has 3 exported fields")
+}
+
+func TestCallMethodWithMap(t *testing.T) {
+	err := RegisterMethodParams(nil, "Greet")
+	assert.EqualError(t, err, "obj must not be nil")
+	err = RegisterMethodParams(&Greeter{}, "Hello")
+	assert.EqualError(t, err, "method: Hello not found")
+	err = RegisterMethodParams(&Greeter{}, "Greet", "name")
+	assert.EqualError(t, err, "fn params num is 2, but got 1 names")
+
+	g := &Greeter{Greeting: "hello"}
+	_, err = CallMethodWithMap(g, "Greet", nil)
+	assert.EqualError(t, err, "param names of method: Greet are not registered")
+
+	err = RegisterMethodParams(Greeter{}, "Greet", "name", "loud")
+	assert.Equal(t, nil, err)
+
+	res, err := CallMethodWithMap(g, "Greet", map[string]interface{}{"name": "bob", "loud": "true"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hello bob!", res[0].Interface())
+
+	_, err = CallMethodWithMap(*g, "Greet", nil)
+	assert.EqualError(t, err, "method: Greet has pointer receiver, obj must be *xreflect.Greeter")
+
+	res, err = CallMethodWithStruct(g, "Greet", struct {
+		Name string
+		Loud bool
+	}{"bob", false})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hello bob", res[0].Interface())
+}
@@ -0,0 +1,112 @@
+package xreflect
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// DetachedCall describes a call started by CallFuncContext which did not finish before its context was done.
+// The goroutine running the call cannot be stopped, it is left running in the background.
+type DetachedCall struct {
+	// Func is the name of the called function, resolved by runtime.FuncForPC.
+	Func string
+	// Err is the error of the context, e.g. context.DeadlineExceeded.
+	Err error
+	// Done is closed when the detached call eventually returns.
+	Done <-chan struct{}
+}
+
+var (
+	detachedCallHookMu sync.RWMutex
+	detachedCallHook   func(DetachedCall)
+)
+
+// SetDetachedCallHook sets the hook which is called each time CallFuncContext or CallMethodContext returns
+// before the called function finishes, leaving its goroutine detached. Passing nil removes the hook.
+func SetDetachedCallHook(hook func(DetachedCall)) {
+	detachedCallHookMu.Lock()
+	defer detachedCallHookMu.Unlock()
+	detachedCallHook = hook
+}
+
+// CallFuncContext has the same functionality as CallFunc, but the call is bound to ctx.
+// If the first parameter of fn is a context.Context and the first argument is not, ctx is injected as the first
+// argument. The function runs in a new goroutine, if ctx is done before it returns, ctx.Err() is returned,
+// e.g. context.DeadlineExceeded, and the goroutine is left detached and reported to the hook set by
+// SetDetachedCallHook. A panic of the function is recovered and returned as a *CallPanicError.
+func CallFuncContext(ctx context.Context, fn interface{}, args ...interface{}) ([]reflect.Value, error) {
+	return callFuncContext(ctx, fn, func() string { return funcName(reflect.ValueOf(fn)) }, args)
+}
+
+// CallFuncTimeout has the same functionality as CallFuncContext, using a background context
+// with the given timeout.
+func CallFuncTimeout(timeout time.Duration, fn interface{}, args ...interface{}) ([]reflect.Value, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return CallFuncContext(ctx, fn, args...)
+}
+
+// CallMethodContext has the same functionality as CallMethod, but the call is bound to ctx.
+// See CallFuncContext for more details.
+func CallMethodContext(ctx context.Context, obj interface{}, method string,
+	params ...interface{}) ([]reflect.Value, error) {
+	methodValue, err := methodByName(obj, method)
+	if err != nil {
+		return nil, err
+	}
+
+	return callFuncContext(ctx, methodValue.Interface(), func() string { return methodName(obj, method) }, params)
+}
+
+func callFuncContext(ctx context.Context, fn interface{}, name func() string,
+	args []interface{}) ([]reflect.Value, error) {
+	if ctx == nil {
+		return nil, errors.New("ctx must not be nil")
+	}
+	if fn != nil {
+		if typ := Type(fn); typ.Kind() == reflect.Func && typ.NumIn() > 0 && typ.In(0) == contextType {
+			if len(args) == 0 || !isContext(args[0]) {
+				args = append([]interface{}{ctx}, args...)
+			}
+		}
+	}
+
+	call, err := prepareCall(fn, args, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var res []reflect.Value
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer recoverCall(name, &res, &err)
+		res, err = extractError(call())
+	}()
+
+	select {
+	case <-done:
+		return res, err
+	case <-ctx.Done():
+		detachedCallHookMu.RLock()
+		hook := detachedCallHook
+		detachedCallHookMu.RUnlock()
+		if hook != nil {
+			hook(DetachedCall{Func: name(), Err: ctx.Err(), Done: done})
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func isContext(arg interface{}) bool {
+	_, ok := arg.(context.Context)
+	return ok
+}
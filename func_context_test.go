@@ -0,0 +1,89 @@
+package xreflect
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var ctxFunc = func(ctx context.Context, a int) (int, error) {
+	return a, ctx.Err()
+}
+
+func sleepFunc(d time.Duration) string {
+	time.Sleep(d)
+	return "done"
+}
+
+type Handler struct {
+	Prefix string
+}
+
+func (h *Handler) Handle(ctx context.Context, s string) string {
+	return h.Prefix + s
+}
+
+func TestCallFuncContext(t *testing.T) {
+	_, err := CallFuncContext(nil, addFunc, 1, 2)
+	assert.EqualError(t, err, "ctx must not be nil")
+
+	_, err = CallFuncContext(context.Background(), nil)
+	assert.EqualError(t, err, "fn must not be nil")
+
+	res, err := CallFuncContext(context.Background(), addFunc, 1, 2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, res[0].Interface())
+
+	res, err = CallFuncContext(context.Background(), ctxFunc, 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, res[0].Interface())
+
+	res, err = CallFuncContext(context.Background(), ctxFunc, context.TODO(), 2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, res[0].Interface())
+
+	_, err = CallFuncContext(context.Background(), ctxFunc)
+	assert.EqualError(t, err, "fn params num is 2, but got 1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = CallFuncContext(ctx, addFunc, 1, 2)
+	assert.Equal(t, true, errors.Is(err, context.Canceled))
+
+	_, err = CallFuncContext(context.Background(), panicFunc, "boom")
+	assert.EqualError(t, err, "call github.com/morrisxyang/xreflect.panicFunc panic: boom")
+
+	res, err = CallMethodContext(context.Background(), &Handler{Prefix: "a"}, "Handle", "b")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "ab", res[0].Interface())
+
+	_, err = CallMethodContext(context.Background(), Handler{}, "Handle", "b")
+	assert.EqualError(t, err, "method: Handle has pointer receiver, obj must be *xreflect.Handler")
+}
+
+func TestCallFuncTimeout(t *testing.T) {
+	detached := make(chan DetachedCall, 1)
+	SetDetachedCallHook(func(call DetachedCall) {
+		detached <- call
+	})
+	defer SetDetachedCallHook(nil)
+
+	res, err := CallFuncTimeout(time.Second, sleepFunc, time.Millisecond)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "done", res[0].Interface())
+
+	_, err = CallFuncTimeout(10*time.Millisecond, sleepFunc, 100*time.Millisecond)
+	assert.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+
+	call := <-detached
+	assert.Equal(t, "github.com/morrisxyang/xreflect.sleepFunc", call.Func)
+	assert.Equal(t, true, errors.Is(call.Err, context.DeadlineExceeded))
+	select {
+	case <-call.Done:
+	case <-time.After(time.Second):
+		t.Error("detached call did not finish")
+	}
+}
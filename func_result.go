@@ -0,0 +1,178 @@
+package xreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// CallResult holds the return values of a function called by reflection.
+// Unlike CallFunc, if the last return value of the function is of type error, it is always separated from the
+// values, whether it is nil or not, so the number of values only depends on the function signature.
+type CallResult struct {
+	values []reflect.Value
+	err    error
+}
+
+// CallFuncResult has the same functionality as CallFunc, but returns the return values as a *CallResult.
+// The returned error is only non-nil if the function could not be called, the error returned by the function
+// itself is available by CallResult.Err.
+func CallFuncResult(fn interface{}, args ...interface{}) (*CallResult, error) {
+	retValues, err := callFunc(fn, args, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCallResult(Type(fn), retValues), nil
+}
+
+// CallFuncSliceResult has the same functionality as CallFuncSlice, but returns the return values
+// as a *CallResult, see CallFuncResult for more details.
+func CallFuncSliceResult(fn interface{}, args ...interface{}) (*CallResult, error) {
+	retValues, err := callFunc(fn, args, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCallResult(Type(fn), retValues), nil
+}
+
+// CallMethodResult has the same functionality as CallMethod, but returns the return values as a *CallResult,
+// see CallFuncResult for more details.
+func CallMethodResult(obj interface{}, method string, params ...interface{}) (*CallResult, error) {
+	methodValue, err := methodByName(obj, method)
+	if err != nil {
+		return nil, err
+	}
+
+	return CallFuncResult(methodValue.Interface(), params...)
+}
+
+// CallFuncInto calls fn with args and stores its return values, except the trailing error, into the pointers
+// of outs, converting them if needed. A nil pointer in outs skips the corresponding value.
+// If the function returns a non-nil error, it is returned and outs are left untouched.
+func CallFuncInto(fn interface{}, outs []interface{}, args ...interface{}) error {
+	res, err := CallFuncResult(fn, args...)
+	if err != nil {
+		return err
+	}
+	if res.Err() != nil {
+		return res.Err()
+	}
+
+	return res.ScanInto(outs...)
+}
+
+func newCallResult(typ reflect.Type, retValues []reflect.Value) *CallResult {
+	res := &CallResult{values: retValues}
+	if returnsError(typ) {
+		res.values = retValues[:len(retValues)-1]
+		if errValue := retValues[len(retValues)-1]; !errValue.IsNil() {
+			res.err = errValue.Interface().(error)
+		}
+	}
+	return res
+}
+
+// Err returns the error returned by the function, if its last return value is of type error.
+func (r *CallResult) Err() error {
+	return r.err
+}
+
+// Len returns the number of return values, excluding the trailing error.
+func (r *CallResult) Len() int {
+	return len(r.values)
+}
+
+// Values returns the return values, excluding the trailing error.
+func (r *CallResult) Values() []reflect.Value {
+	return r.values
+}
+
+// Value returns the i-th return value. It returns an invalid reflect.Value if i is out of range.
+func (r *CallResult) Value(i int) reflect.Value {
+	if i < 0 || i >= len(r.values) {
+		return reflect.Value{}
+	}
+	return r.values[i]
+}
+
+// Interface returns the actual value of the i-th return value, or nil if i is out of range.
+func (r *CallResult) Interface(i int) interface{} {
+	v := r.Value(i)
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// Int returns the i-th return value converted to int64.
+func (r *CallResult) Int(i int) (int64, error) {
+	var res int64
+	err := r.scan(i, &res)
+	return res, err
+}
+
+// Uint returns the i-th return value converted to uint64.
+func (r *CallResult) Uint(i int) (uint64, error) {
+	var res uint64
+	err := r.scan(i, &res)
+	return res, err
+}
+
+// Float returns the i-th return value converted to float64.
+func (r *CallResult) Float(i int) (float64, error) {
+	var res float64
+	err := r.scan(i, &res)
+	return res, err
+}
+
+// Bool returns the i-th return value converted to bool.
+func (r *CallResult) Bool(i int) (bool, error) {
+	var res bool
+	err := r.scan(i, &res)
+	return res, err
+}
+
+// String returns the i-th return value converted to string.
+func (r *CallResult) String(i int) (string, error) {
+	var res string
+	err := r.scan(i, &res)
+	return res, err
+}
+
+// ScanInto stores the return values, excluding the trailing error, into the pointers of dst,
+// converting them if needed. The number of dst must equal the number of values,
+// a nil pointer skips the corresponding value.
+func (r *CallResult) ScanInto(dst ...interface{}) error {
+	if len(dst) != len(r.values) {
+		return fmt.Errorf("result values num is %d, but got %d dst", len(r.values), len(dst))
+	}
+
+	for i, d := range dst {
+		if d == nil {
+			continue
+		}
+		if err := r.scan(i, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *CallResult) scan(i int, dst interface{}) error {
+	if i < 0 || i >= len(r.values) {
+		return fmt.Errorf("result index %d out of range", i)
+	}
+
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return errors.New("dst must be non-nil pointer")
+	}
+	v, err := convertValue(r.values[i], target.Type().Elem())
+	if err != nil {
+		return fmt.Errorf("result %d: %w", i, err)
+	}
+	target.Elem().Set(v)
+	return nil
+}
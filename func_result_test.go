@@ -0,0 +1,116 @@
+package xreflect
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var divFunc = func(a, b int) (int, string, error) {
+	if b == 0 {
+		return 0, "", errors.New("division by zero")
+	}
+	return a / b, "ok", nil
+}
+
+var anyFunc = func() (interface{}, bool) {
+	return 3.0, true
+}
+
+func TestCallFuncResult(t *testing.T) {
+	_, err := CallFuncResult(nil)
+	assert.EqualError(t, err, "fn must not be nil")
+
+	_, err = CallFuncResult(divFunc, 1)
+	assert.EqualError(t, err, "fn params num is 2, but got 1")
+
+	res, err := CallFuncResult(divFunc, 6, 3)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, res.Err())
+	assert.Equal(t, 2, res.Len())
+	assert.Equal(t, 2, len(res.Values()))
+	assert.Equal(t, 2, res.Interface(0))
+	assert.Equal(t, "ok", res.Interface(1))
+	assert.Equal(t, nil, res.Interface(2))
+	assert.Equal(t, false, res.Value(-1).IsValid())
+
+	i, err := res.Int(0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(2), i)
+	u, err := res.Uint(0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(2), u)
+	f, err := res.Float(0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, float64(2), f)
+	s, err := res.String(1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "ok", s)
+	_, err = res.Bool(0)
+	assert.EqualError(t, err, "result 0: cannot use int as bool")
+	_, err = res.String(2)
+	assert.EqualError(t, err, "result index 2 out of range")
+
+	res, err = CallFuncResult(divFunc, 6, 0)
+	assert.Equal(t, nil, err)
+	assert.EqualError(t, res.Err(), "division by zero")
+	assert.Equal(t, 2, res.Len())
+
+	res, err = CallFuncResult(onlyReturnErrorFunc)
+	assert.Equal(t, nil, err)
+	assert.EqualError(t, res.Err(), "error")
+	assert.Equal(t, 0, res.Len())
+
+	res, err = CallFuncResult(anyFunc)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, res.Err())
+	i, err = res.Int(0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(3), i)
+	b, err := res.Bool(1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, b)
+
+	res, err = CallFuncSliceResult(pureVariadicFunc, []int{1, 2})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, res.Interface(0))
+
+	res, err = CallMethodResult(&A{1}, "AddInts", 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, res.Interface(0))
+
+	_, err = CallMethodResult(A{1}, "AddInts", 1)
+	assert.EqualError(t, err, "method: AddInts has pointer receiver, obj must be *xreflect.A")
+}
+
+func TestCallFuncInto(t *testing.T) {
+	var q int64
+	var s string
+	err := CallFuncInto(divFunc, []interface{}{&q, &s}, 6, 3)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(2), q)
+	assert.Equal(t, "ok", s)
+
+	var q2 float32
+	err = CallFuncInto(divFunc, []interface{}{&q2, nil}, 9, 3)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, float32(3), q2)
+
+	err = CallFuncInto(divFunc, []interface{}{&q, &s}, 6, 0)
+	assert.EqualError(t, err, "division by zero")
+	assert.Equal(t, int64(2), q)
+
+	err = CallFuncInto(divFunc, []interface{}{&q}, 6, 3)
+	assert.EqualError(t, err, "result values num is 2, but got 1 dst")
+
+	err = CallFuncInto(divFunc, []interface{}{q, &s}, 6, 3)
+	assert.EqualError(t, err, "dst must be non-nil pointer")
+
+	var b bool
+	err = CallFuncInto(divFunc, []interface{}{&b, &s}, 6, 3)
+	assert.EqualError(t, err, "result 0: cannot use int as bool")
+
+	err = CallFuncInto(addFunc, []interface{}{&q}, 1)
+	assert.EqualError(t, err, "fn params num is 2, but got 1")
+}
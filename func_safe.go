@@ -0,0 +1,99 @@
+package xreflect
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+)
+
+// CallPanicError is returned by the SafeCallX functions when the called function panics.
+type CallPanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	// Func is the name of the called function, resolved by runtime.FuncForPC, e.g. "main.(*Service).Run".
+	Func string
+	// Stack is the stack trace of the panicking goroutine, as formatted by runtime/debug.Stack.
+	Stack []byte
+}
+
+func (e *CallPanicError) Error() string {
+	return fmt.Sprintf("call %s panic: %v", e.Func, e.Value)
+}
+
+// Unwrap returns the panic value if it is an error, so that errors.Is and errors.As can inspect it.
+func (e *CallPanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// SafeCallFunc has the same functionality as CallFunc, but if the called function panics,
+// the panic is recovered and returned as a *CallPanicError.
+func SafeCallFunc(fn interface{}, args ...interface{}) (res []reflect.Value, err error) {
+	defer recoverCall(func() string { return funcName(reflect.ValueOf(fn)) }, &res, &err)
+	return CallFunc(fn, args...)
+}
+
+// SafeCallFuncSlice has the same functionality as CallFuncSlice, but if the called function panics,
+// the panic is recovered and returned as a *CallPanicError.
+func SafeCallFuncSlice(fn interface{}, args ...interface{}) (res []reflect.Value, err error) {
+	defer recoverCall(func() string { return funcName(reflect.ValueOf(fn)) }, &res, &err)
+	return CallFuncSlice(fn, args...)
+}
+
+// SafeCallMethod has the same functionality as CallMethod, but if the called method panics,
+// the panic is recovered and returned as a *CallPanicError.
+func SafeCallMethod(obj interface{}, method string, params ...interface{}) (res []reflect.Value, err error) {
+	defer recoverCall(func() string { return methodName(obj, method) }, &res, &err)
+	return CallMethod(obj, method, params...)
+}
+
+// SafeCallMethodSlice has the same functionality as CallMethodSlice, but if the called method panics,
+// the panic is recovered and returned as a *CallPanicError.
+func SafeCallMethodSlice(obj interface{}, method string, params ...interface{}) (res []reflect.Value, err error) {
+	defer recoverCall(func() string { return methodName(obj, method) }, &res, &err)
+	return CallMethodSlice(obj, method, params...)
+}
+
+// recoverCall must be deferred directly. It converts a panic into a *CallPanicError,
+// the name is only resolved if a panic happened.
+func recoverCall(name func() string, res *[]reflect.Value, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	*res = nil
+	*err = &CallPanicError{
+		Value: r,
+		Func:  name(),
+		Stack: debug.Stack(),
+	}
+}
+
+// funcName returns the name of the function fn, or an empty string if fn is not a function.
+func funcName(fn reflect.Value) string {
+	if fn.Kind() != reflect.Func || fn.IsNil() {
+		return ""
+	}
+	f := runtime.FuncForPC(fn.Pointer())
+	if f == nil {
+		return ""
+	}
+	return f.Name()
+}
+
+// methodName returns the name of the method of obj, resolved from its method set, because method values all
+// share the same code pointer.
+func methodName(obj interface{}, method string) string {
+	typ := reflect.TypeOf(obj)
+	if typ == nil {
+		return method
+	}
+	m, ok := typ.MethodByName(method)
+	if !ok || !m.Func.IsValid() {
+		return typ.String() + "." + method
+	}
+	return funcName(m.Func)
+}
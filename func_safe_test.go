@@ -0,0 +1,63 @@
+package xreflect
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func panicFunc(s string) int {
+	panic(s)
+}
+
+var panicErrorFunc = func(nums ...int) int {
+	panic(io.EOF)
+}
+
+type Panicker struct{}
+
+func (p *Panicker) Explode(s string) {
+	panic(s)
+}
+
+func TestSafeCallFunc(t *testing.T) {
+	res, err := SafeCallFunc(addFunc, 1, 2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, res[0].Interface())
+
+	_, err = SafeCallFunc(addFunc, 1)
+	assert.EqualError(t, err, "fn params num is 2, but got 1")
+
+	res, err = SafeCallFunc(panicFunc, "boom")
+	assert.Nil(t, res)
+	assert.EqualError(t, err, "call github.com/morrisxyang/xreflect.panicFunc panic: boom")
+	var panicErr *CallPanicError
+	assert.Equal(t, true, errors.As(err, &panicErr))
+	assert.Equal(t, "boom", panicErr.Value)
+	assert.Equal(t, "github.com/morrisxyang/xreflect.panicFunc", panicErr.Func)
+	assert.Equal(t, true, strings.Contains(string(panicErr.Stack), "panicFunc"))
+
+	_, err = SafeCallFuncSlice(panicErrorFunc, []int{1})
+	assert.Equal(t, true, errors.Is(err, io.EOF))
+	assert.Equal(t, true, errors.As(err, &panicErr))
+	assert.Equal(t, true, strings.HasPrefix(panicErr.Func, "github.com/morrisxyang/xreflect."))
+}
+
+func TestSafeCallMethod(t *testing.T) {
+	res, err := SafeCallMethod(&A{1}, "AddOne")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, res[0].Interface())
+
+	_, err = SafeCallMethod(&Panicker{}, "Explode", "boom")
+	assert.EqualError(t, err, "call github.com/morrisxyang/xreflect.(*Panicker).Explode panic: boom")
+
+	_, err = SafeCallMethodSlice(&Panicker{}, "Explode", "boom")
+	assert.EqualError(t, err, "fn must be variadic")
+
+	res, err = SafeCallMethodSlice(&A{1}, "AddInts", []int{1})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, res[0].Interface())
+}
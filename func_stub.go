@@ -0,0 +1,161 @@
+package xreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// FuncSpy records the calls of a func field replaced by Stub or Spy.
+type FuncSpy struct {
+	mu      sync.Mutex
+	calls   [][]interface{}
+	restore func()
+}
+
+// Calls returns the arguments of each recorded call, with variadic arguments flattened.
+func (s *FuncSpy) Calls() [][]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	calls := make([][]interface{}, len(s.calls))
+	copy(calls, s.calls)
+	return calls
+}
+
+// CallCount returns the number of recorded calls.
+func (s *FuncSpy) CallCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.calls)
+}
+
+// Restore puts the original func back into the field. It can be called several times,
+// typically with defer or t.Cleanup.
+func (s *FuncSpy) Restore() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.restore != nil {
+		s.restore()
+		s.restore = nil
+	}
+}
+
+func (s *FuncSpy) record(next CallHandler) CallHandler {
+	return func(args []reflect.Value) ([]reflect.Value, error) {
+		values := make([]interface{}, len(args))
+		for i, arg := range args {
+			values[i] = arg.Interface()
+		}
+		s.mu.Lock()
+		s.calls = append(s.calls, values)
+		s.mu.Unlock()
+		return next(args)
+	}
+}
+
+// Stub replaces the func field fieldName of obj with a function which records its calls and always returns
+// returns, converted to the result types. The number of returns must equal the number of results.
+// Private fields are supported, like SetPrivateField. The obj must be a pointer to a structure.
+func Stub(obj interface{}, fieldName string, returns ...interface{}) (*FuncSpy, error) {
+	field, err := funcField(obj, fieldName)
+	if err != nil {
+		return nil, err
+	}
+
+	typ := field.Type()
+	if len(returns) != typ.NumOut() {
+		return nil, fmt.Errorf("field: %s results num is %d, but got %d returns", fieldName, typ.NumOut(), len(returns))
+	}
+	results := make([]reflect.Value, len(returns))
+	for i, r := range returns {
+		v, err := convertValue(reflect.ValueOf(r), typ.Out(i))
+		if err != nil {
+			return nil, fmt.Errorf("field: %s result %d: %w", fieldName, i, err)
+		}
+		results[i] = v
+	}
+
+	stub := reflect.MakeFunc(typ, func([]reflect.Value) []reflect.Value {
+		return results
+	})
+	return replaceFuncField(field, stub)
+}
+
+// Spy replaces the func field fieldName of obj with a function which records its calls and then calls the
+// original func, or returns zero values if it is nil.
+// Private fields are supported, like SetPrivateField. The obj must be a pointer to a structure.
+func Spy(obj interface{}, fieldName string) (*FuncSpy, error) {
+	field, err := funcField(obj, fieldName)
+	if err != nil {
+		return nil, err
+	}
+
+	original := field
+	if field.IsNil() {
+		typ := field.Type()
+		original = reflect.MakeFunc(typ, func([]reflect.Value) []reflect.Value {
+			results := make([]reflect.Value, typ.NumOut())
+			for i := range results {
+				results[i] = reflect.Zero(typ.Out(i))
+			}
+			return results
+		})
+	}
+	return replaceFuncField(field, original)
+}
+
+func replaceFuncField(field reflect.Value, fn reflect.Value) (*FuncSpy, error) {
+	spy := &FuncSpy{}
+	wrapped, err := Wrap(fn.Interface(), spy.record)
+	if err != nil {
+		return nil, err
+	}
+
+	original := reflect.New(field.Type()).Elem()
+	original.Set(field)
+	spy.restore = func() {
+		field.Set(original)
+	}
+	field.Set(reflect.ValueOf(wrapped))
+	return spy, nil
+}
+
+// funcField returns the settable func field fieldName of the struct pointer obj.
+func funcField(obj interface{}, fieldName string) (reflect.Value, error) {
+	var empty reflect.Value
+	if obj == nil {
+		return empty, errors.New("obj must not be nil")
+	}
+	if fieldName == "" {
+		return empty, errors.New("field name must not be empty")
+	}
+	if !isSupportedType(obj, []reflect.Kind{reflect.Pointer}) {
+		return empty, errors.New("obj must be struct pointer")
+	}
+	target := Value(obj)
+	if !isSupportedKind(target.Kind(), []reflect.Kind{reflect.Struct}) {
+		return empty, errors.New("obj must be struct pointer")
+	}
+
+	structField, ok := target.Type().FieldByName(fieldName)
+	if !ok {
+		return empty, fmt.Errorf("field: %s is invalid", fieldName)
+	}
+	field, err := target.FieldByIndexErr(structField.Index)
+	if err != nil {
+		return empty, fmt.Errorf("field: %s is invalid: %w", fieldName, err)
+	}
+	if field.Kind() != reflect.Func {
+		return empty, fmt.Errorf("field: %s is not func", fieldName)
+	}
+	if !field.CanSet() {
+		// deal private field
+		field = reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+	}
+	return field, nil
+}
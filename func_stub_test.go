@@ -0,0 +1,101 @@
+package xreflect
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FetchService struct {
+	Now   func() time.Time
+	Fetch func(url string, retries ...int) ([]byte, error)
+	log   func(string)
+	Name  string
+}
+
+func TestStub(t *testing.T) {
+	svc := &FetchService{
+		Fetch: func(url string, retries ...int) ([]byte, error) {
+			return []byte("real"), nil
+		},
+	}
+
+	_, err := Stub(nil, "Fetch")
+	assert.EqualError(t, err, "obj must not be nil")
+	_, err = Stub(svc, "")
+	assert.EqualError(t, err, "field name must not be empty")
+	_, err = Stub(*svc, "Fetch")
+	assert.EqualError(t, err, "obj must be struct pointer")
+	_, err = Stub(svc, "Fetch2")
+	assert.EqualError(t, err, "field: Fetch2 is invalid")
+	_, err = Stub(svc, "Name")
+	assert.EqualError(t, err, "field: Name is not func")
+	_, err = Stub(svc, "Fetch", []byte("stub"))
+	assert.EqualError(t, err, "field: Fetch results num is 2, but got 1 returns")
+	_, err = Stub(svc, "Fetch", 1, nil)
+	assert.EqualError(t, err, "field: Fetch result 0: cannot use int as []uint8")
+
+	spy, err := Stub(svc, "Fetch", "stub", errors.New("offline"))
+	assert.Equal(t, nil, err)
+	b, err := svc.Fetch("a", 1, 2)
+	assert.EqualError(t, err, "offline")
+	assert.Equal(t, []byte("stub"), b)
+	_, _ = svc.Fetch("b")
+	assert.Equal(t, 2, spy.CallCount())
+	assert.Equal(t, [][]interface{}{{"a", 1, 2}, {"b"}}, spy.Calls())
+
+	spy.Restore()
+	spy.Restore()
+	b, err = svc.Fetch("a")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []byte("real"), b)
+	assert.Equal(t, 2, spy.CallCount())
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	spy, err = Stub(svc, "Now", now)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, now, svc.Now())
+	spy.Restore()
+	assert.Nil(t, svc.Now)
+
+	type wrapper struct {
+		*FetchService
+	}
+	_, err = Stub(&wrapper{}, "Now", now)
+	assert.EqualError(t, err, "field: Now is invalid: reflect: indirection through nil pointer to embedded struct field FetchService")
+	w := &wrapper{FetchService: &FetchService{}}
+	_, err = Stub(w, "Now", now)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, now, w.Now())
+}
+
+func TestSpy(t *testing.T) {
+	var logs []string
+	svc := &FetchService{
+		log: func(s string) {
+			logs = append(logs, s)
+		},
+	}
+
+	spy, err := Spy(svc, "log")
+	assert.Equal(t, nil, err)
+	svc.log("a")
+	svc.log("b")
+	assert.Equal(t, []string{"a", "b"}, logs)
+	assert.Equal(t, [][]interface{}{{"a"}, {"b"}}, spy.Calls())
+	spy.Restore()
+	svc.log("c")
+	assert.Equal(t, 2, spy.CallCount())
+	assert.Equal(t, []string{"a", "b", "c"}, logs)
+
+	spy, err = Spy(svc, "Fetch")
+	assert.Equal(t, nil, err)
+	b, err := svc.Fetch("a")
+	assert.Equal(t, nil, err)
+	assert.Nil(t, b)
+	assert.Equal(t, 1, spy.CallCount())
+	spy.Restore()
+	assert.Nil(t, svc.Fetch)
+}
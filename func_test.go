@@ -161,6 +161,67 @@ func TestCallFunc(t *testing.T) {
 
 }
 
+var int64ParamFunc = func(a int64, b uint8) int64 {
+	return a + int64(b)
+}
+
+var stringerParamFunc = func(s fmt.Stringer) string {
+	if s == nil {
+		return "nil"
+	}
+	return s.String()
+}
+
+var nillableParamFunc = func(p *int, m map[string]int, f func() int) bool {
+	return p == nil && m == nil && f == nil
+}
+
+func TestCallFuncConvertArgs(t *testing.T) {
+	res, err := CallFunc(int64ParamFunc, 1, 2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(3), res[0].Interface())
+
+	res, err = CallFunc(int64ParamFunc, "1", 2.0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(3), res[0].Interface())
+
+	_, err = CallFunc(int64ParamFunc, 1, 256)
+	assert.EqualError(t, err, "fn param 1: cannot use int 256 as uint8: overflow")
+
+	_, err = CallFunc(int64ParamFunc, "a", 1)
+	assert.EqualError(t, err, "fn param 0: cannot use string \"a\" as int64")
+
+	res, err = CallFunc(int64ParamFunc, nil, 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(1), res[0].Interface())
+
+	res, err = CallFunc(stringerParamFunc, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "nil", res[0].Interface())
+
+	_, err = CallFunc(stringerParamFunc, 1)
+	assert.EqualError(t, err, "fn param 0: cannot use int as fmt.Stringer: missing methods")
+
+	res, err = CallFunc(nillableParamFunc, nil, nil, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, res[0].Interface())
+
+	_, err = CallFunc(pureVariadicFunc, 1, 2)
+	assert.EqualError(t, err, "fn param 0: cannot use int as *int")
+
+	p := 1
+	_, err = CallFunc(func(p int) {}, &p)
+	assert.EqualError(t, err, "fn param 0: cannot use *int as int")
+
+	_, err = CallFunc(func(p *int64) { *p = 5 }, &p)
+	assert.EqualError(t, err, "fn param 0: cannot use *int as *int64")
+	assert.Equal(t, 1, p)
+
+	res, err = CallFuncSlice(variadicFunc, nil, []interface{}{1, 2})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, res[0].Interface())
+}
+
 type A struct {
 	Int int
 }
@@ -201,7 +262,7 @@ func TestCallMethod(t *testing.T) {
 	assert.Equal(t, 2, res[0].Interface())
 
 	res, err = CallMethod(A{1}, "AddOne")
-	assert.EqualError(t, err, "method: AddOne not found")
+	assert.EqualError(t, err, "method: AddOne has pointer receiver, obj must be *xreflect.A")
 
 	res, err = CallMethod(&A{1}, "AddInts")
 	assert.Equal(t, nil, err)
@@ -228,4 +289,53 @@ func TestCallMethod(t *testing.T) {
 	res, err = CallMethodSlice(&A{1}, "AddInts", []int{1, 1})
 	assert.Equal(t, nil, err)
 	assert.Equal(t, 3, res[0].Interface())
+
+	_, err = CallMethodSlice(A{1}, "AddInts", []int{1, 1})
+	assert.EqualError(t, err, "method: AddInts has pointer receiver, obj must be *xreflect.A")
+}
+
+type Celsius float64
+
+func (c Celsius) Fahrenheit() float64 {
+	return float64(c)*9/5 + 32
+}
+
+func (c *Celsius) Add(d float64) {
+	*c += Celsius(d)
+}
+
+type Set map[string]struct{}
+
+func (s Set) Add(keys ...string) int {
+	for _, k := range keys {
+		s[k] = struct{}{}
+	}
+	return len(s)
+}
+
+func TestCallMethodNamedType(t *testing.T) {
+	res, err := CallMethod(Celsius(100), "Fahrenheit")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, float64(212), res[0].Interface())
+
+	c := Celsius(1)
+	_, err = CallMethod(&c, "Add", float64(1))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Celsius(2), c)
+
+	_, err = CallMethod(c, "Add", float64(1))
+	assert.EqualError(t, err, "method: Add has pointer receiver, obj must be *xreflect.Celsius")
+
+	s := Set{}
+	res, err = CallMethod(s, "Add", "a", "b")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, res[0].Interface())
+	assert.Equal(t, 2, len(s))
+
+	res, err = CallMethodSlice(s, "Add", []string{"b", "c"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, res[0].Interface())
+
+	_, err = CallMethod(s, "Remove")
+	assert.EqualError(t, err, "method: Remove not found")
 }
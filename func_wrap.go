@@ -0,0 +1,145 @@
+package xreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// CallHandler performs a reflective call. The args are flattened as with CallFunc, i.e. each variadic argument
+// is a separate value, and if the function's last result is of type error, it is returned separately
+// from the other results, which are always complete whether the error is nil or not.
+type CallHandler func(args []reflect.Value) ([]reflect.Value, error)
+
+// CallMiddleware wraps a CallHandler, for example to add logging, timing, retry or argument validation.
+type CallMiddleware func(next CallHandler) CallHandler
+
+// Wrap returns a function of the exact same type as fn, which passes each call through the middlewares before
+// calling fn, so the result can be asserted back to the type of fn. The first middleware is the outermost one.
+// A middleware can return nil results with a non-nil error, the wrapped function then returns zero values
+// and the error. If fn does not return an error, a non-nil error from the middlewares causes a panic.
+// The args passed down by the middlewares are converted to the parameter types of fn, see CallFunc,
+// and a conversion error is returned like an error of fn.
+func Wrap(fn interface{}, middlewares ...CallMiddleware) (interface{}, error) {
+	if fn == nil {
+		return nil, errors.New("fn must not be nil")
+	}
+	val := reflect.ValueOf(fn)
+	if val.Kind() != reflect.Func || val.IsNil() {
+		return nil, errors.New("fn must be func")
+	}
+	for _, m := range middlewares {
+		if m == nil {
+			return nil, errors.New("middleware must not be nil")
+		}
+	}
+
+	typ := val.Type()
+	hasErr := returnsError(typ)
+	handler := CallHandler(func(args []reflect.Value) ([]reflect.Value, error) {
+		in, err := wrapArgs(typ, args)
+		if err != nil {
+			return nil, err
+		}
+		res := val.Call(in)
+		if !hasErr {
+			return res, nil
+		}
+		if errValue := res[len(res)-1]; !errValue.IsNil() {
+			err = errValue.Interface().(error)
+		}
+		return res[:len(res)-1], err
+	})
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	wrapped := reflect.MakeFunc(typ, func(in []reflect.Value) []reflect.Value {
+		args := in
+		if typ.IsVariadic() {
+			variadic := in[len(in)-1]
+			args = make([]reflect.Value, 0, len(in)-1+variadic.Len())
+			args = append(args, in[:len(in)-1]...)
+			for i := 0; i < variadic.Len(); i++ {
+				args = append(args, variadic.Index(i))
+			}
+		}
+
+		res, err := handler(args)
+		return wrapResults(typ, hasErr, res, err)
+	})
+	return wrapped.Interface(), nil
+}
+
+// RetryMiddleware returns a CallMiddleware which calls next again while it returns a non-nil error,
+// at most attempts times in total.
+func RetryMiddleware(attempts int) CallMiddleware {
+	return func(next CallHandler) CallHandler {
+		return func(args []reflect.Value) ([]reflect.Value, error) {
+			res, err := next(args)
+			for i := 1; i < attempts && err != nil; i++ {
+				res, err = next(args)
+			}
+			return res, err
+		}
+	}
+}
+
+// wrapArgs converts the args passed down by the middlewares to the parameter types of the func type typ,
+// so that a middleware replacing an argument with a value of another type gets an error instead of a panic.
+func wrapArgs(typ reflect.Type, args []reflect.Value) ([]reflect.Value, error) {
+	if !typ.IsVariadic() && len(args) != typ.NumIn() {
+		return nil, fmt.Errorf("fn params num is %d, but got %d", typ.NumIn(), len(args))
+	}
+	if typ.IsVariadic() && len(args) < typ.NumIn()-1 {
+		return nil, fmt.Errorf("fn params num is %d at least, but got %d", typ.NumIn()-1, len(args))
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		v, err := convertArg(arg, paramType(typ, i))
+		if err != nil {
+			return nil, fmt.Errorf("fn param %d: %w", i, err)
+		}
+		in[i] = v
+	}
+	return in, nil
+}
+
+// wrapResults builds the results of a function of type typ from the results of a CallHandler.
+func wrapResults(typ reflect.Type, hasErr bool, res []reflect.Value, err error) []reflect.Value {
+	if err != nil && !hasErr {
+		panic(fmt.Errorf("fn does not return error, but middleware returned: %w", err))
+	}
+
+	n := typ.NumOut()
+	if hasErr {
+		n--
+	}
+	if res == nil && err != nil {
+		res = make([]reflect.Value, n)
+		for i := range res {
+			res[i] = reflect.Zero(typ.Out(i))
+		}
+	}
+	if len(res) != n {
+		panic(fmt.Sprintf("fn results num is %d, but middleware returned %d", n, len(res)))
+	}
+
+	out := make([]reflect.Value, 0, typ.NumOut())
+	for i, v := range res {
+		cv, convErr := convertValue(v, typ.Out(i))
+		if convErr != nil {
+			panic(fmt.Errorf("fn result %d: %w", i, convErr))
+		}
+		out = append(out, cv)
+	}
+	if hasErr {
+		if err == nil {
+			out = append(out, reflect.Zero(errorType))
+		} else {
+			out = append(out, reflect.ValueOf(&err).Elem())
+		}
+	}
+	return out
+}
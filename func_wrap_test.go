@@ -0,0 +1,157 @@
+package xreflect
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrap(t *testing.T) {
+	_, err := Wrap(nil)
+	assert.EqualError(t, err, "fn must not be nil")
+
+	_, err = Wrap(1)
+	assert.EqualError(t, err, "fn must be func")
+
+	_, err = Wrap(addFunc, nil)
+	assert.EqualError(t, err, "middleware must not be nil")
+
+	var logs []string
+	logging := func(name string) CallMiddleware {
+		return func(next CallHandler) CallHandler {
+			return func(args []reflect.Value) ([]reflect.Value, error) {
+				logs = append(logs, name+" before")
+				res, err := next(args)
+				logs = append(logs, name+" after")
+				return res, err
+			}
+		}
+	}
+
+	wrapped, err := Wrap(addFunc, logging("a"), logging("b"))
+	assert.Equal(t, nil, err)
+	add := wrapped.(func(int, int) int)
+	assert.Equal(t, 3, add(1, 2))
+	assert.Equal(t, []string{"a before", "b before", "b after", "a after"}, logs)
+
+	var seen []interface{}
+	capture := func(next CallHandler) CallHandler {
+		return func(args []reflect.Value) ([]reflect.Value, error) {
+			seen = seen[:0]
+			for _, arg := range args {
+				seen = append(seen, arg.Interface())
+			}
+			return next(args)
+		}
+	}
+	wrapped, err = Wrap(greetFunc, capture)
+	assert.Equal(t, nil, err)
+	greet := wrapped.(func(string, int, ...string) string)
+	assert.Equal(t, "hi bob!?", greet("bob", 1, "!", "?"))
+	assert.Equal(t, []interface{}{"bob", 1, "!", "?"}, seen)
+	assert.Equal(t, "hi bob", greet("bob", 1))
+	assert.Equal(t, []interface{}{"bob", 1}, seen)
+
+	validate := func(next CallHandler) CallHandler {
+		return func(args []reflect.Value) ([]reflect.Value, error) {
+			if args[1].Int() == 0 {
+				return nil, errors.New("b must not be zero")
+			}
+			return next(args)
+		}
+	}
+	wrapped, err = Wrap(divFunc, validate)
+	assert.Equal(t, nil, err)
+	div := wrapped.(func(int, int) (int, string, error))
+	q, s, err := div(6, 3)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, q)
+	assert.Equal(t, "ok", s)
+	q, s, err = div(6, 0)
+	assert.EqualError(t, err, "b must not be zero")
+	assert.Equal(t, 0, q)
+	assert.Equal(t, "", s)
+
+	replace := func(arg interface{}) CallMiddleware {
+		return func(next CallHandler) CallHandler {
+			return func(args []reflect.Value) ([]reflect.Value, error) {
+				args[1] = reflect.ValueOf(arg)
+				return next(args)
+			}
+		}
+	}
+	wrapped, err = Wrap(divFunc, replace(int64(2)))
+	assert.Equal(t, nil, err)
+	div = wrapped.(func(int, int) (int, string, error))
+	q, _, err = div(6, 3)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, q)
+	wrapped, err = Wrap(divFunc, replace("x"))
+	assert.Equal(t, nil, err)
+	div = wrapped.(func(int, int) (int, string, error))
+	q, _, err = div(6, 3)
+	assert.EqualError(t, err, "fn param 1: cannot use string \"x\" as int")
+	assert.Equal(t, 0, q)
+
+	incr := func(p *int) error {
+		if p == nil {
+			return errors.New("nil p")
+		}
+		*p++
+		return nil
+	}
+	wrapped, err = Wrap(incr, replaceFirst(5))
+	assert.Equal(t, nil, err)
+	n := 1
+	assert.EqualError(t, wrapped.(func(*int) error)(&n), "fn param 0: cannot use int as *int")
+	wrapped, err = Wrap(incr, replaceFirst(nil))
+	assert.Equal(t, nil, err)
+	assert.EqualError(t, wrapped.(func(*int) error)(&n), "nil p")
+	wrapped, err = Wrap(incr, replaceFirst(&n))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, wrapped.(func(*int) error)(new(int)))
+	assert.Equal(t, 2, n)
+
+	wrapped, err = Wrap(addFunc, validate)
+	assert.Equal(t, nil, err)
+	assert.PanicsWithError(t, "fn does not return error, but middleware returned: b must not be zero", func() {
+		wrapped.(func(int, int) int)(1, 0)
+	})
+}
+
+// replaceFirst returns a CallMiddleware which replaces the first argument with arg, or an invalid value if nil.
+func replaceFirst(arg interface{}) CallMiddleware {
+	return func(next CallHandler) CallHandler {
+		return func(args []reflect.Value) ([]reflect.Value, error) {
+			args[0] = reflect.ValueOf(arg)
+			return next(args)
+		}
+	}
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	calls := 0
+	flaky := func(s string) (string, error) {
+		calls++
+		if calls < 3 {
+			return "", errors.New("flaky")
+		}
+		return s, nil
+	}
+
+	wrapped, err := Wrap(flaky, RetryMiddleware(2))
+	assert.Equal(t, nil, err)
+	_, err = wrapped.(func(string) (string, error))("a")
+	assert.EqualError(t, err, "flaky")
+	assert.Equal(t, 2, calls)
+
+	calls = 0
+	wrapped, err = Wrap(flaky, RetryMiddleware(3))
+	assert.Equal(t, nil, err)
+	s, err := wrapped.(func(string) (string, error))("a")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "a", s)
+	assert.Equal(t, 3, calls)
+}
@@ -0,0 +1,132 @@
+package xreflect
+
+import (
+	"errors"
+	"reflect"
+)
+
+// InterfaceReport describes how a type satisfies, or fails to satisfy, an interface.
+type InterfaceReport struct {
+	// Type is the type of the inspected obj.
+	Type reflect.Type
+	// Interface is the inspected interface type.
+	Interface reflect.Type
+	// Implements reports whether Type implements Interface.
+	Implements bool
+	// PointerImplements reports whether the pointer to Type implements Interface while Type itself does not.
+	// This is the common case of methods declared on a pointer receiver.
+	PointerImplements bool
+	// Present contains the names of the interface methods that Type has with the expected signature.
+	Present []string
+	// Missing contains the names of the interface methods that are not in the method set of Type.
+	Missing []string
+	// PointerOnly contains the names of the missing methods which are in the method set of the pointer to Type.
+	PointerOnly []string
+	// Mismatched contains the interface methods that Type has, but with a different signature.
+	Mismatched []MethodMismatch
+}
+
+// MethodMismatch describes a method whose signature differs from the one declared by an interface.
+type MethodMismatch struct {
+	Name string
+	// Want is the signature declared by the interface, e.g. "func(int) error".
+	Want string
+	// Got is the signature found on the inspected type.
+	Got string
+}
+
+// ImplementsReport returns a detailed report on whether obj implements the given interface in,
+// listing the present, missing and mismatched methods.
+// Unexported interface methods are reported as present if obj implements the interface, and as missing if
+// it does not although all the exported methods are present, otherwise they are not reported.
+// The in must be a pointer to an interface, e.g. (*io.Reader)(nil).
+func ImplementsReport(obj interface{}, in interface{}) (*InterfaceReport, error) {
+	if obj == nil {
+		return nil, errors.New("obj must not be nil")
+	}
+	interfaceType, err := interfaceTypeOf(in)
+	if err != nil {
+		return nil, err
+	}
+
+	objType := reflect.TypeOf(obj)
+	report := &InterfaceReport{
+		Type:       objType,
+		Interface:  interfaceType,
+		Implements: objType.Implements(interfaceType),
+	}
+	if !report.Implements && objType.Kind() != reflect.Pointer && objType.Kind() != reflect.Interface {
+		report.PointerImplements = reflect.PointerTo(objType).Implements(interfaceType)
+	}
+
+	var unexported []string
+	for i := 0; i < interfaceType.NumMethod(); i++ {
+		want := interfaceType.Method(i)
+		if want.PkgPath != "" {
+			unexported = append(unexported, want.Name)
+			continue
+		}
+		got, ok := objType.MethodByName(want.Name)
+		if !ok {
+			report.Missing = append(report.Missing, want.Name)
+			if objType.Kind() == reflect.Pointer || objType.Kind() == reflect.Interface {
+				continue
+			}
+			if _, ok := reflect.PointerTo(objType).MethodByName(want.Name); ok {
+				report.PointerOnly = append(report.PointerOnly, want.Name)
+			}
+			continue
+		}
+
+		skipReceiver := objType.Kind() != reflect.Interface
+		if methodFuncType(got.Type, skipReceiver) != want.Type {
+			report.Mismatched = append(report.Mismatched, MethodMismatch{
+				Name: want.Name,
+				Want: methodTypeString(want.Type, false),
+				Got:  methodTypeString(got.Type, skipReceiver),
+			})
+			continue
+		}
+		report.Present = append(report.Present, want.Name)
+	}
+
+	// Unexported methods cannot be looked up by reflection, they can only be deduced from Implements.
+	switch {
+	case report.Implements:
+		report.Present = append(report.Present, unexported...)
+	case len(report.Missing) == 0 && len(report.Mismatched) == 0:
+		report.Missing = append(report.Missing, unexported...)
+		if report.PointerImplements {
+			report.PointerOnly = append(report.PointerOnly, unexported...)
+		}
+	}
+	return report, nil
+}
+
+// methodFuncType returns the func type of a method, without the receiver if skipReceiver is true,
+// so that it can be compared with the type of an interface method.
+func methodFuncType(typ reflect.Type, skipReceiver bool) reflect.Type {
+	if !skipReceiver {
+		return typ
+	}
+	in := make([]reflect.Type, typ.NumIn()-1)
+	for i := range in {
+		in[i] = typ.In(i + 1)
+	}
+	out := make([]reflect.Type, typ.NumOut())
+	for i := range out {
+		out[i] = typ.Out(i)
+	}
+	return reflect.FuncOf(in, out, typ.IsVariadic())
+}
+
+func interfaceTypeOf(in interface{}) (reflect.Type, error) {
+	if in == nil {
+		return nil, errors.New("in must not be nil")
+	}
+	typ := reflect.TypeOf(in)
+	if typ.Kind() != reflect.Pointer || typ.Elem().Kind() != reflect.Interface {
+		return nil, errors.New("in must be interface pointer")
+	}
+	return typ.Elem(), nil
+}
@@ -0,0 +1,149 @@
+package xreflect
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Shape interface {
+	Area() float64
+	Scale(factor float64)
+	Name() string
+}
+
+type Square struct {
+	Side float64
+}
+
+func (s Square) Area() float64 {
+	return s.Side * s.Side
+}
+
+func (s *Square) Scale(factor float64) {
+	s.Side *= factor
+}
+
+func (s Square) Name() string {
+	return "square"
+}
+
+type Circle struct {
+	Radius float64
+}
+
+func (c Circle) Area() int {
+	return int(3 * c.Radius * c.Radius)
+}
+
+func (c Circle) Scale(factor float64) {}
+
+func TestImplementsReport(t *testing.T) {
+	_, err := ImplementsReport(nil, (*Shape)(nil))
+	assert.EqualError(t, err, "obj must not be nil")
+
+	_, err = ImplementsReport(Square{}, nil)
+	assert.EqualError(t, err, "in must not be nil")
+
+	_, err = ImplementsReport(Square{}, Square{})
+	assert.EqualError(t, err, "in must be interface pointer")
+
+	_, err = ImplementsReport(Square{}, &Square{})
+	assert.EqualError(t, err, "in must be interface pointer")
+
+	report, err := ImplementsReport(&Square{}, (*Shape)(nil))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, report.Implements)
+	assert.Equal(t, false, report.PointerImplements)
+	assert.Equal(t, []string{"Area", "Name", "Scale"}, report.Present)
+	assert.Empty(t, report.Missing)
+	assert.Empty(t, report.Mismatched)
+
+	report, err = ImplementsReport(Square{}, (*Shape)(nil))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, report.Implements)
+	assert.Equal(t, true, report.PointerImplements)
+	assert.Equal(t, []string{"Area", "Name"}, report.Present)
+	assert.Equal(t, []string{"Scale"}, report.Missing)
+	assert.Equal(t, []string{"Scale"}, report.PointerOnly)
+
+	report, err = ImplementsReport(Circle{}, (*Shape)(nil))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, report.Implements)
+	assert.Equal(t, false, report.PointerImplements)
+	assert.Equal(t, []string{"Scale"}, report.Present)
+	assert.Equal(t, []string{"Name"}, report.Missing)
+	assert.Empty(t, report.PointerOnly)
+	assert.Equal(t, []MethodMismatch{{Name: "Area", Want: "func() float64", Got: "func() int"}}, report.Mismatched)
+
+	report, err = ImplementsReport(errors.New(""), (*io.Reader)(nil))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, report.Implements)
+	assert.Equal(t, []string{"Read"}, report.Missing)
+}
+
+func TestImplementsNotPointer(t *testing.T) {
+	assert.Equal(t, false, Implements(Square{}, nil))
+	assert.Equal(t, false, Implements(Square{}, Square{}))
+	assert.Equal(t, true, Implements(&Square{}, (*Shape)(nil)))
+}
+
+func TestMethodTypeString(t *testing.T) {
+	assert.Equal(t, "func()", methodTypeString(Type(func() {}), false))
+	assert.Equal(t, "func(int, ...string) (bool, error)",
+		methodTypeString(Type(func(int, ...string) (bool, error) { return false, nil }), false))
+	scale, _ := reflect.TypeOf(&Square{}).MethodByName("Scale")
+	assert.Equal(t, "func(float64)", methodTypeString(scale.Type, true))
+}
+
+type sameNameUser struct{}
+
+type sameNameImpl struct{}
+
+func (sameNameImpl) Get() sameNameUser { return sameNameUser{} }
+
+func TestImplementsReportSameName(t *testing.T) {
+	// a different type which is printed as xreflect.sameNameUser as well, like types of different packages
+	type sameNameUser struct{ ID int }
+	type getter interface {
+		Get() sameNameUser
+	}
+
+	report, err := ImplementsReport(sameNameImpl{}, (*getter)(nil))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, report.Implements)
+	assert.Empty(t, report.Present)
+	assert.Equal(t, []MethodMismatch{{Name: "Get", Want: "func() xreflect.sameNameUser",
+		Got: "func() xreflect.sameNameUser"}}, report.Mismatched)
+}
+
+type sealed interface {
+	Name() string
+	sealed()
+}
+
+func (s *Square) sealed() {}
+
+func TestImplementsReportUnexported(t *testing.T) {
+	report, err := ImplementsReport(&Square{}, (*sealed)(nil))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, report.Implements)
+	assert.Equal(t, []string{"Name", "sealed"}, report.Present)
+	assert.Empty(t, report.Missing)
+
+	report, err = ImplementsReport(Square{}, (*sealed)(nil))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, report.Implements)
+	assert.Equal(t, true, report.PointerImplements)
+	assert.Equal(t, []string{"Name"}, report.Present)
+	assert.Equal(t, []string{"sealed"}, report.Missing)
+	assert.Equal(t, []string{"sealed"}, report.PointerOnly)
+
+	report, err = ImplementsReport(Circle{}, (*sealed)(nil))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"Name"}, report.Missing)
+	assert.Empty(t, report.Present)
+}
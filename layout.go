@@ -0,0 +1,101 @@
+package xreflect
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// StructLayout describes the memory layout of a structure type.
+type StructLayout struct {
+	Type   reflect.Type
+	Size   uintptr
+	Align  uintptr
+	Fields []FieldLayout
+	// Wasted is the total number of padding bytes.
+	Wasted uintptr
+	// SuggestedOrder is a field order which minimizes padding, with larger alignments first,
+	// and OptimalSize is the size of the structure with this order.
+	SuggestedOrder []string
+	OptimalSize    uintptr
+}
+
+// FieldLayout describes the memory layout of a structure field.
+type FieldLayout struct {
+	Name   string
+	Type   reflect.Type
+	Offset uintptr
+	Size   uintptr
+	Align  uintptr
+	// Padding is the number of padding bytes after the field.
+	Padding uintptr
+	// Nested is the layout of the field type if it is a structure.
+	Nested *StructLayout
+}
+
+// Layout returns the memory layout of the structure type of obj, including the padding after each field and a
+// suggested field order minimizing it. The layouts of nested structure fields are computed recursively.
+// The obj can either be a structure, a pointer to structure or a reflect.Type.
+func Layout(obj interface{}) (*StructLayout, error) {
+	if obj == nil {
+		return nil, errors.New("obj must not be nil")
+	}
+	typ := TypePenetrateElem(obj)
+	if typ.Kind() != reflect.Struct {
+		return nil, errors.New("obj must be struct")
+	}
+	return structLayout(typ), nil
+}
+
+func structLayout(typ reflect.Type) *StructLayout {
+	l := &StructLayout{
+		Type:   typ,
+		Size:   typ.Size(),
+		Align:  uintptr(typ.Align()),
+		Fields: make([]FieldLayout, typ.NumField()),
+	}
+	for i := range l.Fields {
+		field := typ.Field(i)
+		f := FieldLayout{
+			Name:   field.Name,
+			Type:   field.Type,
+			Offset: field.Offset,
+			Size:   field.Type.Size(),
+			Align:  uintptr(field.Type.FieldAlign()),
+		}
+		end := typ.Size()
+		if i+1 < typ.NumField() {
+			end = typ.Field(i + 1).Offset
+		}
+		f.Padding = end - f.Offset - f.Size
+		if field.Type.Kind() == reflect.Struct {
+			f.Nested = structLayout(field.Type)
+		}
+		l.Wasted += f.Padding
+		l.Fields[i] = f
+	}
+
+	order := make([]FieldLayout, len(l.Fields))
+	copy(order, l.Fields)
+	// zero-size fields go first, because a trailing one is padded
+	sort.SliceStable(order, func(i, j int) bool {
+		if (order[i].Size == 0) != (order[j].Size == 0) {
+			return order[i].Size == 0
+		}
+		return order[i].Align > order[j].Align
+	})
+	var offset uintptr
+	for _, f := range order {
+		l.SuggestedOrder = append(l.SuggestedOrder, f.Name)
+		offset = alignUp(offset, f.Align) + f.Size
+	}
+	if len(order) > 0 && order[len(order)-1].Size == 0 && offset > 0 {
+		offset++
+	}
+	l.OptimalSize = alignUp(offset, l.Align)
+	return l
+}
+
+func alignUp(n, align uintptr) uintptr {
+	return (n + align - 1) &^ (align - 1)
+}
@@ -0,0 +1,72 @@
+package xreflect
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type layoutInner struct {
+	A bool
+	B int32
+}
+
+type layoutHot struct {
+	Flag  bool
+	Count int64
+	Ok    bool
+	Inner layoutInner
+	Small int16
+	Empty struct{}
+}
+
+func TestLayout(t *testing.T) {
+	_, err := Layout(nil)
+	assert.EqualError(t, err, "obj must not be nil")
+	_, err = Layout(1)
+	assert.EqualError(t, err, "obj must be struct")
+
+	l, err := Layout(&layoutHot{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, unsafe.Sizeof(layoutHot{}), l.Size)
+	assert.Equal(t, unsafe.Alignof(layoutHot{}), l.Align)
+	assert.Equal(t, 6, len(l.Fields))
+
+	var sum uintptr
+	for _, f := range l.Fields {
+		sum += f.Size + f.Padding
+	}
+	assert.Equal(t, l.Size, sum)
+
+	flag := l.Fields[0]
+	assert.Equal(t, "Flag", flag.Name)
+	assert.Equal(t, uintptr(0), flag.Offset)
+	assert.Equal(t, uintptr(1), flag.Size)
+	assert.Equal(t, unsafe.Offsetof(layoutHot{}.Count)-1, flag.Padding)
+	assert.Equal(t, unsafe.Offsetof(layoutHot{}.Inner), l.Fields[3].Offset)
+
+	inner := l.Fields[3].Nested
+	assert.Equal(t, reflect.TypeOf(layoutInner{}), inner.Type)
+	assert.Equal(t, uintptr(3), inner.Wasted)
+	assert.Equal(t, []string{"B", "A"}, inner.SuggestedOrder)
+	assert.Equal(t, uintptr(8), inner.OptimalSize)
+
+	assert.Equal(t, []string{"Empty", "Count", "Inner", "Small", "Flag", "Ok"}, l.SuggestedOrder)
+
+	type optimal struct {
+		Empty struct{}
+		Count int64
+		Inner layoutInner
+		Small int16
+		Flag  bool
+		Ok    bool
+	}
+	assert.Equal(t, unsafe.Sizeof(optimal{}), l.OptimalSize)
+	assert.Equal(t, l.Size-l.OptimalSize <= l.Wasted, true)
+
+	l, err = Layout(reflect.TypeOf(struct{}{}))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uintptr(0), l.OptimalSize)
+}
@@ -0,0 +1,181 @@
+package xreflect
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// FieldError is an error associated with the path of a field, such as "DB.Pool".
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors is a list of FieldError, in the order in which the fields were processed.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+// InvokeRecursive performs a deep traversal of obj, and calls the method `method` with args on every exported
+// field value which has it, in declaration order, parents before their nested fields.
+// Nested structures are traversed through struct fields and non-nil pointers to structures, other pointers are
+// not followed, and a pointer to a structure which has already been traversed, such as a back-pointer to a
+// parent, is neither invoked nor traversed again.
+// The obj itself is not invoked. An embedded field is not invoked if its parent was, since the parent's
+// method is either promoted from it or responsible for it. Nil fields are skipped, and panics are recovered
+// like SafeCallMethod. All errors returned or raised by the calls are returned as FieldErrors.
+// The obj should be a pointer to a structure, so that methods with pointer receivers can be found.
+func InvokeRecursive(obj interface{}, method string, args ...interface{}) error {
+	return invokeRecursive(obj, method, args, false)
+}
+
+// InvokeRecursiveReverse has the same functionality as InvokeRecursive, but calls the methods in the reverse
+// order, nested fields before their parents, which is suitable for methods like Close.
+func InvokeRecursiveReverse(obj interface{}, method string, args ...interface{}) error {
+	return invokeRecursive(obj, method, args, true)
+}
+
+type invokeTarget struct {
+	path string
+	recv reflect.Value
+}
+
+// visitKey identifies a visited pointer, map or slice, to stop on cycles.
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+	// len distinguishes slices sharing the same backing array.
+	len int
+}
+
+// invocation collects the targets of InvokeRecursive.
+type invocation struct {
+	method  string
+	targets []invokeTarget
+	covered map[string]bool
+	visited map[visitKey]bool
+}
+
+func invokeRecursive(obj interface{}, method string, args []interface{}, reverse bool) error {
+	if obj == nil {
+		return errors.New("obj must not be nil")
+	}
+	if method == "" {
+		return errors.New("method must not be empty")
+	}
+	val := Value(obj)
+	if !val.IsValid() {
+		return errors.New("obj must not be nil")
+	}
+	if val.Kind() != reflect.Struct {
+		return errors.New("obj must be struct")
+	}
+
+	inv := &invocation{
+		method:  method,
+		covered: make(map[string]bool),
+		visited: make(map[visitKey]bool),
+	}
+	if root := reflect.ValueOf(obj); root.Kind() == reflect.Ptr {
+		inv.visited[visitKey{ptr: root.Pointer(), typ: root.Type()}] = true
+	}
+	inv.walk(val, "")
+
+	targets := inv.targets
+	if reverse {
+		for i, j := 0, len(targets)-1; i < j; i, j = i+1, j-1 {
+			targets[i], targets[j] = targets[j], targets[i]
+		}
+	}
+	var errs FieldErrors
+	for _, target := range targets {
+		if err := invokeMethod(target.recv, method, args); err != nil {
+			errs = append(errs, &FieldError{Path: target.path, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (inv *invocation) walk(val reflect.Value, prefix string) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		value := val.Field(i)
+		path := joinPath(prefix, field.Name)
+
+		isStructPtr := value.Kind() == reflect.Ptr && !value.IsNil() && value.Elem().Kind() == reflect.Struct
+		if isStructPtr {
+			key := visitKey{ptr: value.Pointer(), typ: value.Type()}
+			if inv.visited[key] {
+				continue
+			}
+			inv.visited[key] = true
+		}
+
+		if field.Anonymous && inv.covered[prefix] {
+			inv.covered[path] = true
+		} else if recv, ok := methodReceiver(value, inv.method); ok {
+			inv.targets = append(inv.targets, invokeTarget{path: path, recv: recv})
+			inv.covered[path] = true
+		}
+
+		switch {
+		case value.Kind() == reflect.Struct:
+			inv.walk(value, path)
+		case isStructPtr:
+			inv.walk(value.Elem(), path)
+		}
+	}
+}
+
+// methodReceiver returns the field value, or its address if possible, if it has the method `method`.
+func methodReceiver(value reflect.Value, method string) (reflect.Value, bool) {
+	if !value.CanInterface() {
+		return reflect.Value{}, false
+	}
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		if value.IsNil() {
+			return reflect.Value{}, false
+		}
+	}
+	if value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Ptr && value.CanAddr() {
+		value = value.Addr()
+	}
+	return value, value.MethodByName(method).IsValid()
+}
+
+func invokeMethod(recv reflect.Value, method string, args []interface{}) (err error) {
+	var res []reflect.Value
+	defer recoverCall(func() string { return methodName(recv.Interface(), method) }, &res, &err)
+	_, err = callValueFunc(recv.MethodByName(method), args, false)
+	return err
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,146 @@
+package xreflect
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var lcCalls []string
+
+type LcBase struct{}
+
+func (b *LcBase) Init() error {
+	lcCalls = append(lcCalls, "base init")
+	return nil
+}
+
+type LcDB struct {
+	Name string
+	Fail bool
+}
+
+func (d *LcDB) Init() error {
+	lcCalls = append(lcCalls, d.Name+" init")
+	if d.Fail {
+		return errors.New("connect failed")
+	}
+	return nil
+}
+
+func (d *LcDB) Start(ctx context.Context) error {
+	lcCalls = append(lcCalls, d.Name+" start")
+	return ctx.Err()
+}
+
+func (d *LcDB) Close() error {
+	lcCalls = append(lcCalls, d.Name+" close")
+	return nil
+}
+
+type LcCache struct {
+	LcBase
+	DB LcDB
+}
+
+type LcBroken struct{}
+
+func (LcBroken) Init() error {
+	panic("broken")
+}
+
+type LcApp struct {
+	LcBase
+	Primary *LcDB
+	Replica *LcDB
+	Cache   LcCache
+	Broken  LcBroken
+	Service interface{}
+	private *LcDB
+}
+
+func (a *LcApp) Init() error {
+	lcCalls = append(lcCalls, "app init")
+	return nil
+}
+
+func TestInvokeRecursive(t *testing.T) {
+	assert.EqualError(t, InvokeRecursive(nil, "Init"), "obj must not be nil")
+	assert.EqualError(t, InvokeRecursive(&LcApp{}, ""), "method must not be empty")
+
+	lcCalls = nil
+	app := &LcApp{
+		Primary: &LcDB{Name: "primary", Fail: true},
+		Cache:   LcCache{DB: LcDB{Name: "cache"}},
+		Service: &LcDB{Name: "service"},
+		private: &LcDB{Name: "private"},
+	}
+	err := InvokeRecursive(app, "Init")
+	assert.Equal(t, []string{"base init", "primary init", "base init", "cache init", "service init"}, lcCalls)
+	var errs FieldErrors
+	assert.Equal(t, true, errors.As(err, &errs))
+	assert.Equal(t, 2, len(errs))
+	assert.Equal(t, "Primary", errs[0].Path)
+	assert.EqualError(t, errs[0].Err, "connect failed")
+	assert.Equal(t, "Broken", errs[1].Path)
+	assert.EqualError(t, errs[1].Err, "call github.com/morrisxyang/xreflect.(*LcBroken).Init panic: broken")
+	assert.EqualError(t, err, "Primary: connect failed; Broken: call github.com/morrisxyang/xreflect.(*LcBroken).Init panic: broken")
+
+	lcCalls = nil
+	app.Primary.Fail = false
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.Equal(t, nil, InvokeRecursive(app, "Start", ctx))
+	assert.Equal(t, []string{"primary start", "cache start", "service start"}, lcCalls)
+	cancel()
+	assert.EqualError(t, InvokeRecursive(app, "Start", ctx),
+		"Primary: context canceled; Cache.DB: context canceled; Service: context canceled")
+	assert.EqualError(t, InvokeRecursive(app, "Start"), "Primary: fn params num is 1, but got 0; "+
+		"Cache.DB: fn params num is 1, but got 0; Service: fn params num is 1, but got 0")
+
+	lcCalls = nil
+	assert.Equal(t, nil, InvokeRecursiveReverse(app, "Close"))
+	assert.Equal(t, []string{"service close", "cache close", "primary close"}, lcCalls)
+}
+
+func TestInvokeRecursiveEmbedded(t *testing.T) {
+	type Outer struct {
+		Cache LcCache
+	}
+	type Promoted struct {
+		Outer
+	}
+
+	lcCalls = nil
+	assert.Equal(t, nil, InvokeRecursive(&Promoted{}, "Init"))
+	assert.Equal(t, []string{"base init", " init"}, lcCalls)
+}
+
+type LcNode struct {
+	Name   string
+	Port   *int
+	Parent *LcNode
+	Next   *LcNode
+}
+
+func (n *LcNode) Init() error {
+	lcCalls = append(lcCalls, n.Name+" init")
+	return nil
+}
+
+func TestInvokeRecursiveCycle(t *testing.T) {
+	port := 80
+	root := &LcNode{Name: "root", Port: &port}
+	child := &LcNode{Name: "child", Port: &port, Parent: root}
+	root.Next = child
+	child.Next = &LcNode{Name: "grandchild", Parent: child}
+
+	lcCalls = nil
+	assert.Equal(t, nil, InvokeRecursive(root, "Init"))
+	assert.Equal(t, []string{"child init", "grandchild init"}, lcCalls)
+
+	var nilNode *LcNode
+	assert.EqualError(t, InvokeRecursive(nilNode, "Init"), "obj must not be nil")
+	assert.EqualError(t, InvokeRecursive(&port, "Init"), "obj must be struct")
+}
@@ -0,0 +1,213 @@
+package xreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// MethodInfo describes a method in the method set of a type.
+type MethodInfo struct {
+	// Name is the method name.
+	Name string
+	// In contains the parameter types, excluding the receiver.
+	In []reflect.Type
+	// Out contains the result types.
+	Out []reflect.Type
+	// Variadic reports whether the last parameter is variadic.
+	Variadic bool
+	// ReturnsError reports whether the last result is an error.
+	ReturnsError bool
+	// PointerReceiver reports whether the method is only in the method set of the pointer type,
+	// i.e. it has a pointer receiver.
+	PointerReceiver bool
+	// PromotedFrom is the path of the embedded field the method is promoted from, such as "Base" or "Base.Inner".
+	// It is empty if the method is declared on the type itself.
+	PromotedFrom string
+	// Method is the underlying reflect.Method of the pointer type, or of the interface type.
+	Method reflect.Method
+}
+
+// Signature returns the signature of the method without receiver, e.g. "func(int, ...string) (bool, error)".
+func (m MethodInfo) Signature() string {
+	return methodTypeString(m.Method.Type, m.Method.Func.IsValid())
+}
+
+// Methods returns the methods of obj, including both the value and the pointer method set.
+// Methods with a pointer receiver are marked with PointerReceiver.
+// The obj can be any value or pointer to value, unexported methods are not included.
+func Methods(obj interface{}) ([]MethodInfo, error) {
+	return SelectMethods(obj, func(int, MethodInfo) bool {
+		return true
+	})
+}
+
+// SelectMethods has the same functionality as Methods, but only the methods for which the function f returns true
+// will be returned.
+func SelectMethods(obj interface{}, f func(int, MethodInfo) bool) ([]MethodInfo, error) {
+	var res []MethodInfo
+	err := RangeMethods(obj, func(i int, m MethodInfo) bool {
+		if f(i, m) {
+			res = append(res, m)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RangeMethods iterates over all methods of obj and calls function f on each method.
+// If function f returns false, the iteration stops.
+func RangeMethods(obj interface{}, f func(int, MethodInfo) bool) error {
+	if obj == nil {
+		return errors.New("obj must not be nil")
+	}
+
+	typ := Type(obj)
+	ptrTyp := typ
+	if typ.Kind() != reflect.Interface {
+		ptrTyp = reflect.PointerTo(typ)
+	}
+	for i := 0; i < ptrTyp.NumMethod(); i++ {
+		if !f(i, newMethodInfo(typ, ptrTyp.Method(i))) {
+			break
+		}
+	}
+	return nil
+}
+
+// Method returns the MethodInfo of the method named name of obj.
+// The obj can be any value or pointer to value.
+func Method(obj interface{}, name string) (MethodInfo, error) {
+	var empty MethodInfo
+	if obj == nil {
+		return empty, errors.New("obj must not be nil")
+	}
+
+	typ := Type(obj)
+	ptrTyp := typ
+	if typ.Kind() != reflect.Interface {
+		ptrTyp = reflect.PointerTo(typ)
+	}
+	method, ok := ptrTyp.MethodByName(name)
+	if !ok {
+		return empty, fmt.Errorf("method: %s not found", name)
+	}
+	return newMethodInfo(typ, method), nil
+}
+
+// HasMethod checks if obj has a method named name, in either its value or its pointer method set.
+// The obj can be any value or pointer to value.
+func HasMethod(obj interface{}, name string) (bool, error) {
+	if obj == nil {
+		return false, errors.New("obj must not be nil")
+	}
+
+	_, err := Method(obj, name)
+	return err == nil, nil
+}
+
+// MethodSignature returns the signature of the method named name of obj without receiver,
+// e.g. "func(int, ...string) (bool, error)".
+// The obj can be any value or pointer to value.
+func MethodSignature(obj interface{}, name string) (string, error) {
+	method, err := Method(obj, name)
+	if err != nil {
+		return "", err
+	}
+
+	return method.Signature(), nil
+}
+
+func newMethodInfo(typ reflect.Type, method reflect.Method) MethodInfo {
+	info := MethodInfo{
+		Name:     method.Name,
+		Variadic: method.Type.IsVariadic(),
+		Method:   method,
+	}
+
+	// Method types of interfaces have no receiver.
+	start := 1
+	if typ.Kind() == reflect.Interface {
+		start = 0
+	}
+	for i := start; i < method.Type.NumIn(); i++ {
+		info.In = append(info.In, method.Type.In(i))
+	}
+	for i := 0; i < method.Type.NumOut(); i++ {
+		info.Out = append(info.Out, method.Type.Out(i))
+	}
+	info.ReturnsError = returnsError(method.Type)
+	if typ.Kind() == reflect.Interface {
+		return info
+	}
+
+	valueMethod, ok := typ.MethodByName(method.Name)
+	info.PointerReceiver = !ok
+	if !ok {
+		valueMethod = method
+	}
+	if typ.Kind() == reflect.Struct && isGeneratedMethod(valueMethod) {
+		info.PromotedFrom = promotedFrom(typ, method.Name)
+	}
+	return info
+}
+
+// isGeneratedMethod reports whether the method is a wrapper generated by the compiler,
+// which is the case for methods promoted from embedded fields.
+func isGeneratedMethod(method reflect.Method) bool {
+	fn := runtime.FuncForPC(method.Func.Pointer())
+	if fn == nil {
+		return false
+	}
+	file, _ := fn.FileLine(fn.Entry())
+	return file == "<autogenerated>"
+}
+
+// promotedFrom returns the path of the shallowest embedded field of the struct type typ which provides
+// the method named name.
+func promotedFrom(typ reflect.Type, name string) string {
+	type embedded struct {
+		path string
+		typ  reflect.Type
+	}
+
+	var level []embedded
+	for i := 0; i < typ.NumField(); i++ {
+		if field := typ.Field(i); field.Anonymous {
+			level = append(level, embedded{field.Name, field.Type})
+		}
+	}
+	for len(level) > 0 {
+		var next []embedded
+		for _, e := range level {
+			ft := e.typ
+			if ft.Kind() != reflect.Pointer && ft.Kind() != reflect.Interface {
+				ft = reflect.PointerTo(ft)
+			}
+			if _, ok := ft.MethodByName(name); ok {
+				return e.path
+			}
+
+			st := e.typ
+			if st.Kind() == reflect.Pointer {
+				st = st.Elem()
+			}
+			if st.Kind() != reflect.Struct {
+				continue
+			}
+			for i := 0; i < st.NumField(); i++ {
+				if field := st.Field(i); field.Anonymous {
+					next = append(next, embedded{e.path + "." + field.Name, field.Type})
+				}
+			}
+		}
+		level = next
+	}
+	return ""
+}
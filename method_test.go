@@ -0,0 +1,139 @@
+package xreflect
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Engine struct {
+	Power int
+}
+
+func (e Engine) Start() error {
+	return nil
+}
+
+func (e *Engine) Tune(power int) {
+	e.Power = power
+}
+
+type Car struct {
+	Engine
+	Name string
+}
+
+func (c Car) Drive(speeds ...int) (int, error) {
+	if len(speeds) == 0 {
+		return 0, errors.New("no speed")
+	}
+	return speeds[0], nil
+}
+
+func (c *Car) Rename(name string) {
+	c.Name = name
+}
+
+func TestMethods(t *testing.T) {
+	_, err := Methods(nil)
+	assert.EqualError(t, err, "obj must not be nil")
+
+	methods, err := Methods(Car{})
+	assert.Equal(t, nil, err)
+	var names []string
+	for _, m := range methods {
+		names = append(names, m.Name)
+	}
+	assert.Equal(t, []string{"Drive", "Rename", "Start", "Tune"}, names)
+
+	drive := methods[0]
+	assert.Equal(t, []reflect.Type{reflect.TypeOf([]int{})}, drive.In)
+	assert.Equal(t, []reflect.Type{reflect.TypeOf(0), errorType}, drive.Out)
+	assert.Equal(t, true, drive.Variadic)
+	assert.Equal(t, true, drive.ReturnsError)
+	assert.Equal(t, false, drive.PointerReceiver)
+	assert.Equal(t, "", drive.PromotedFrom)
+
+	rename := methods[1]
+	assert.Equal(t, true, rename.PointerReceiver)
+	assert.Equal(t, false, rename.ReturnsError)
+	assert.Equal(t, "", rename.PromotedFrom)
+
+	start := methods[2]
+	assert.Equal(t, false, start.PointerReceiver)
+	assert.Equal(t, true, start.ReturnsError)
+	assert.Equal(t, "Engine", start.PromotedFrom)
+
+	tune := methods[3]
+	assert.Equal(t, true, tune.PointerReceiver)
+	assert.Equal(t, "Engine", tune.PromotedFrom)
+
+	ptrMethods, err := Methods(&Car{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, len(methods), len(ptrMethods))
+	for i := range methods {
+		assert.Equal(t, methods[i].Name, ptrMethods[i].Name)
+		assert.Equal(t, methods[i].PointerReceiver, ptrMethods[i].PointerReceiver)
+	}
+
+	methods, err = Methods((*io.ReadCloser)(nil))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(methods))
+	assert.Equal(t, "Close", methods[0].Name)
+	assert.Equal(t, "func() error", methods[0].Signature())
+	assert.Equal(t, false, methods[0].PointerReceiver)
+}
+
+func TestSelectMethods(t *testing.T) {
+	_, err := SelectMethods(nil, nil)
+	assert.EqualError(t, err, "obj must not be nil")
+
+	methods, err := SelectMethods(&Car{}, func(i int, m MethodInfo) bool {
+		return m.PointerReceiver
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(methods))
+	assert.Equal(t, "Rename", methods[0].Name)
+	assert.Equal(t, "Tune", methods[1].Name)
+
+	err = RangeMethods(nil, nil)
+	assert.EqualError(t, err, "obj must not be nil")
+
+	var names []string
+	err = RangeMethods(Car{}, func(i int, m MethodInfo) bool {
+		names = append(names, m.Name)
+		return i < 1
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"Drive", "Rename"}, names)
+}
+
+func TestHasMethod(t *testing.T) {
+	_, err := HasMethod(nil, "Drive")
+	assert.EqualError(t, err, "obj must not be nil")
+
+	ok, err := HasMethod(Car{}, "Rename")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+
+	ok, err = HasMethod(Car{}, "Fly")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, ok)
+
+	_, err = Method(Car{}, "Fly")
+	assert.EqualError(t, err, "method: Fly not found")
+
+	_, err = MethodSignature(nil, "Drive")
+	assert.EqualError(t, err, "obj must not be nil")
+
+	s, err := MethodSignature(Car{}, "Drive")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "func(...int) (int, error)", s)
+
+	s, err = MethodSignature(&Car{}, "Tune")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "func(int)", s)
+}
@@ -0,0 +1,234 @@
+package xreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Modifier transforms a string for Normalize.
+type Modifier func(string) string
+
+var (
+	modifiersMu sync.RWMutex
+	modifiers   = map[string]Modifier{
+		"trim":            strings.TrimSpace,
+		"lower":           strings.ToLower,
+		"upper":           strings.ToUpper,
+		"title":           titleCase,
+		"collapse_spaces": collapseSpaces,
+	}
+)
+
+// RegisterModifier registers a custom modifier which can then be used in `mod` tags by name.
+// Built-in modifiers cannot be replaced.
+func RegisterModifier(name string, modifier Modifier) error {
+	if name == "" {
+		return errors.New("name must not be empty")
+	}
+	if modifier == nil {
+		return errors.New("modifier must not be nil")
+	}
+
+	modifiersMu.Lock()
+	defer modifiersMu.Unlock()
+	if _, ok := modifiers[name]; ok {
+		return fmt.Errorf("modifier: %s is already registered", name)
+	}
+	modifiers[name] = modifier
+	return nil
+}
+
+// Normalize applies the modifiers listed in the `mod` tags of the exported fields of obj, in order,
+// e.g. `mod:"trim,lower"`, to the strings of the fields: strings, pointers to strings, and the elements of
+// slices, arrays and maps of strings. Nested structures are traversed, including through pointers, slices,
+// arrays and maps. The built-in modifiers are trim, lower, upper, title and collapse_spaces, which replaces
+// runs of white space with a single space. Custom modifiers can be added by RegisterModifier.
+// All the `mod` tags are resolved before obj is modified, so an unknown modifier leaves obj unchanged.
+// The obj must be a pointer to a structure.
+func Normalize(obj interface{}) error {
+	if obj == nil {
+		return errors.New("obj must not be nil")
+	}
+	if reflect.TypeOf(obj).Kind() != reflect.Ptr || Type(obj).Kind() != reflect.Struct {
+		return errors.New("obj must be struct pointer")
+	}
+
+	// The first pass only resolves the modifiers of all reachable fields, so that an invalid tag
+	// is reported before anything is modified.
+	n := &normalizer{visited: make(map[visitKey]bool), mods: make(map[reflect.Type][][]Modifier)}
+	if err := n.normalize(reflect.ValueOf(obj), "", nil); err != nil {
+		return err
+	}
+	n.apply = true
+	n.visited = make(map[visitKey]bool)
+	return n.normalize(reflect.ValueOf(obj), "", nil)
+}
+
+type normalizer struct {
+	apply   bool
+	visited map[visitKey]bool
+	// mods are the modifiers of the fields of each structure type, resolved from their `mod` tags.
+	mods map[reflect.Type][][]Modifier
+}
+
+// normalize applies mods to the strings in the settable val, and the `mod` tags to the nested structures.
+// If n.apply is false, val is only traversed to resolve the modifiers.
+func (n *normalizer) normalize(val reflect.Value, path string, mods []Modifier) error {
+	switch val.Kind() {
+	case reflect.String:
+		if !n.apply {
+			return nil
+		}
+		s := val.String()
+		for _, mod := range mods {
+			s = mod(s)
+		}
+		val.SetString(s)
+	case reflect.Ptr:
+		if val.IsNil() {
+			return nil
+		}
+		key := visitKey{ptr: val.Pointer(), typ: val.Type()}
+		if n.visited[key] {
+			return nil
+		}
+		n.visited[key] = true
+		return n.normalize(val.Elem(), path, mods)
+	case reflect.Interface:
+		if val.IsNil() {
+			return nil
+		}
+		if !n.apply {
+			return n.normalize(val.Elem(), path, mods)
+		}
+		elem := reflect.New(val.Elem().Type()).Elem()
+		elem.Set(val.Elem())
+		if err := n.normalize(elem, path, mods); err != nil {
+			return err
+		}
+		val.Set(elem)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			if err := n.normalize(val.Index(i), fmt.Sprintf("%s[%d]", path, i), mods); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			if !n.apply {
+				if err := n.normalize(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), mods); err != nil {
+					return err
+				}
+				continue
+			}
+			elem := reflect.New(val.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			if err := n.normalize(elem, fmt.Sprintf("%s[%v]", path, iter.Key()), mods); err != nil {
+				return err
+			}
+			val.SetMapIndex(iter.Key(), elem)
+		}
+	case reflect.Struct:
+		typ := val.Type()
+		typeMods, err := n.typeModifiers(typ, path)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			if !typ.Field(i).IsExported() {
+				continue
+			}
+			fieldPath := joinPath(path, typ.Field(i).Name)
+			if err := n.normalize(val.Field(i), fieldPath, typeMods[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// typeModifiers returns the modifiers of each field of the struct type typ, found at path.
+func (n *normalizer) typeModifiers(typ reflect.Type, path string) ([][]Modifier, error) {
+	if typeMods, ok := n.mods[typ]; ok {
+		return typeMods, nil
+	}
+
+	typeMods := make([][]Modifier, typ.NumField())
+	for i := range typeMods {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldMods, err := fieldModifiers(field)
+		if err != nil {
+			return nil, fmt.Errorf("field: %s: %w", joinPath(path, field.Name), err)
+		}
+		typeMods[i] = fieldMods
+	}
+	n.mods[typ] = typeMods
+	return typeMods, nil
+}
+
+func fieldModifiers(field reflect.StructField) ([]Modifier, error) {
+	tag := field.Tag.Get("mod")
+	if tag == "" || tag == "-" {
+		return nil, nil
+	}
+
+	modifiersMu.RLock()
+	defer modifiersMu.RUnlock()
+	var mods []Modifier
+	for _, name := range strings.Split(tag, ",") {
+		mod, ok := modifiers[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown modifier: %s", name)
+		}
+		mods = append(mods, mod)
+	}
+	return mods, nil
+}
+
+// titleCase upper-cases the first letter of each word and lower-cases the other letters.
+func titleCase(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	start := true
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			start = true
+			b.WriteRune(r)
+			continue
+		}
+		if start {
+			b.WriteRune(unicode.ToUpper(r))
+			start = false
+		} else {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// collapseSpaces replaces each run of white space with a single space.
+func collapseSpaces(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			if !space {
+				b.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
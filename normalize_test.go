@@ -0,0 +1,96 @@
+package xreflect
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type normAddress struct {
+	City string `mod:"trim,title"`
+}
+
+type normRequest struct {
+	Name     string            `mod:"trim,collapse_spaces,title"`
+	Email    *string           `mod:"trim,lower"`
+	Tags     []string          `mod:"trim,upper"`
+	Labels   map[string]string `mod:"trim"`
+	Address  normAddress
+	Previous []*normAddress
+	Others   map[string]normAddress
+	Any      interface{}
+	Raw      string
+	Self     *normRequest
+	secret   string `mod:"trim"`
+}
+
+func TestNormalize(t *testing.T) {
+	assert.EqualError(t, Normalize(nil), "obj must not be nil")
+	assert.EqualError(t, Normalize(normRequest{}), "obj must be struct pointer")
+
+	email := "  Bob@Example.COM "
+	r := &normRequest{
+		Name:     "  jOHN \t  smith ",
+		Email:    &email,
+		Tags:     []string{" a ", "b"},
+		Labels:   map[string]string{"k": " v "},
+		Address:  normAddress{City: " new york"},
+		Previous: []*normAddress{{City: "paris "}, nil},
+		Others:   map[string]normAddress{"x": {City: " rome"}},
+		Any:      &normAddress{City: " oslo"},
+		Raw:      " raw ",
+		secret:   " s ",
+	}
+	r.Self = r
+	assert.Equal(t, nil, Normalize(r))
+	assert.Equal(t, "John Smith", r.Name)
+	assert.Equal(t, "bob@example.com", email)
+	assert.Equal(t, []string{"A", "B"}, r.Tags)
+	assert.Equal(t, map[string]string{"k": "v"}, r.Labels)
+	assert.Equal(t, "New York", r.Address.City)
+	assert.Equal(t, "Paris", r.Previous[0].City)
+	assert.Equal(t, "Rome", r.Others["x"].City)
+	assert.Equal(t, "Oslo", r.Any.(*normAddress).City)
+	assert.Equal(t, " raw ", r.Raw)
+	assert.Equal(t, " s ", r.secret)
+}
+
+func TestRegisterModifier(t *testing.T) {
+	assert.EqualError(t, RegisterModifier("", nil), "name must not be empty")
+	assert.EqualError(t, RegisterModifier("x", nil), "modifier must not be nil")
+	assert.EqualError(t, RegisterModifier("trim", strings.TrimSpace), "modifier: trim is already registered")
+
+	assert.Equal(t, nil, RegisterModifier("norm_digits", func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, s)
+	}))
+
+	type phone struct {
+		Number string `mod:"norm_digits"`
+		Ext    string `mod:"trim,nope"`
+	}
+	p := &phone{Number: "+1 (555) 010-99"}
+	assert.EqualError(t, Normalize(p), "field: Ext: unknown modifier: nope")
+	assert.Equal(t, "+1 (555) 010-99", p.Number)
+
+	type contact struct {
+		Name  string `mod:"trim"`
+		Phone interface{}
+	}
+	c := &contact{Name: " bob ", Phone: []interface{}{p}}
+	assert.EqualError(t, Normalize(c), "field: Phone[0].Ext: unknown modifier: nope")
+	assert.Equal(t, " bob ", c.Name)
+	assert.Equal(t, "+1 (555) 010-99", p.Number)
+
+	type validPhone struct {
+		Number string `mod:"norm_digits"`
+	}
+	v := &validPhone{Number: "+1 (555) 010-99"}
+	assert.Equal(t, nil, Normalize(v))
+	assert.Equal(t, "155501099", v.Number)
+}
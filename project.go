@@ -0,0 +1,204 @@
+package xreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Project returns a nested map containing only the fields of obj selected by fields, such as
+// "name,address{city,zip},orders[*]{id}": a field can be followed by {...} to select fields of a nested structure
+// or pointer to structure, and a slice or array field by [*]{...} to select fields of each element.
+// The field names can be Go names or json tag names, and the map keys are the json tag names when present.
+// Fields tagged `json:"-"` cannot be selected.
+// The fields are validated against the type of obj before any value is read.
+// The obj can either be a structure or pointer to structure, a nil pointer results in a nil map.
+func Project(obj interface{}, fields string) (map[string]interface{}, error) {
+	if obj == nil {
+		return nil, errors.New("obj must not be nil")
+	}
+	typ := Type(obj)
+	if typ.Kind() != reflect.Struct {
+		return nil, errors.New("obj must be struct")
+	}
+
+	p := &projectionParser{s: fields}
+	items, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	if err := resolveProjection(typ, items); err != nil {
+		return nil, err
+	}
+
+	if val := reflect.ValueOf(obj); val.Kind() == reflect.Ptr && val.IsNil() {
+		return nil, nil
+	}
+	return projectStruct(Value(obj), items), nil
+}
+
+// projection is a selected field, resolved to its index and key by resolveProjection.
+type projection struct {
+	name     string
+	elems    bool
+	children []*projection
+
+	index []int
+	key   string
+}
+
+type projectionParser struct {
+	s   string
+	pos int
+}
+
+func (p *projectionParser) parse() ([]*projection, error) {
+	if p.s == "" {
+		return nil, errors.New("fields must not be empty")
+	}
+	items, err := p.list()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.s) {
+		return nil, p.unexpected()
+	}
+	return items, nil
+}
+
+func (p *projectionParser) list() ([]*projection, error) {
+	var items []*projection
+	for {
+		item, err := p.item()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.pos >= len(p.s) || p.s[p.pos] != ',' {
+			return items, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *projectionParser) item() (*projection, error) {
+	start := p.pos
+	for p.pos < len(p.s) && !isProjectionDelimiter(p.s[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, p.unexpected()
+	}
+	item := &projection{name: p.s[start:p.pos]}
+
+	if p.pos < len(p.s) && p.s[p.pos] == '[' {
+		if p.pos+3 > len(p.s) || p.s[p.pos:p.pos+3] != "[*]" {
+			return nil, p.unexpected()
+		}
+		item.elems = true
+		p.pos += 3
+	}
+	if p.pos < len(p.s) && p.s[p.pos] == '{' {
+		p.pos++
+		children, err := p.list()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.s) || p.s[p.pos] != '}' {
+			return nil, p.unexpected()
+		}
+		p.pos++
+		item.children = children
+	}
+	return item, nil
+}
+
+func (p *projectionParser) unexpected() error {
+	if p.pos >= len(p.s) {
+		return fmt.Errorf("fields: %s is invalid: unexpected end", p.s)
+	}
+	return fmt.Errorf("fields: %s is invalid: unexpected %q at %d", p.s, p.s[p.pos], p.pos)
+}
+
+func isProjectionDelimiter(c byte) bool {
+	return c == ',' || c == '{' || c == '}' || c == '[' || c == ']'
+}
+
+// resolveProjection resolves the fields of the struct type typ selected by items, and checks their types.
+func resolveProjection(typ reflect.Type, items []*projection) error {
+	for _, item := range items {
+		field, ok := fieldByTagOrName(typ, item.name, "json")
+		if !ok || field.Tag.Get("json") == "-" {
+			// fields hidden from JSON are hidden from projections as well
+			return fmt.Errorf("no such field: %s", item.name)
+		}
+		if !field.IsExported() {
+			return fmt.Errorf("field: %s is unexported", field.Name)
+		}
+		item.index = field.Index
+		item.key = tagName(field, "json")
+		if item.key == "" {
+			item.key = field.Name
+		}
+
+		ft := field.Type
+		if item.elems {
+			if ft.Kind() != reflect.Slice && ft.Kind() != reflect.Array {
+				return fmt.Errorf("field: %s is not slice", field.Name)
+			}
+			ft = ft.Elem()
+		}
+		if item.children == nil {
+			continue
+		}
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct {
+			return fmt.Errorf("field: %s is not struct", field.Name)
+		}
+		if err := resolveProjection(ft, item.children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func projectStruct(val reflect.Value, items []*projection) map[string]interface{} {
+	res := make(map[string]interface{}, len(items))
+	for _, item := range items {
+		field, err := val.FieldByIndexErr(item.index)
+		if err != nil {
+			// nil embedded pointer
+			res[item.key] = nil
+			continue
+		}
+		if !item.elems {
+			res[item.key] = projectValue(field, item.children)
+			continue
+		}
+		if field.Kind() == reflect.Slice && field.IsNil() {
+			res[item.key] = nil
+			continue
+		}
+		elems := make([]interface{}, field.Len())
+		for i := range elems {
+			elems[i] = projectValue(field.Index(i), item.children)
+		}
+		res[item.key] = elems
+	}
+	return res
+}
+
+func projectValue(val reflect.Value, children []*projection) interface{} {
+	if children == nil {
+		return val.Interface()
+	}
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	return projectStruct(val, children)
+}
@@ -0,0 +1,78 @@
+package xreflect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type projOrder struct {
+	ID    int     `json:"id"`
+	Total float64 `json:"total"`
+}
+
+type projUser struct {
+	Name    string       `json:"name"`
+	Email   string       `json:"email"`
+	Address *maskAddress `json:"address"`
+	Orders  []projOrder  `json:"orders"`
+	Tags    []string
+	Hash    string `json:"-"`
+	secret  string
+}
+
+func TestProject(t *testing.T) {
+	u := &projUser{
+		Name:    "bob",
+		Email:   "bob@example.com",
+		Address: &maskAddress{City: "Paris", Zip: "75001"},
+		Orders:  []projOrder{{ID: 1, Total: 2}, {ID: 3, Total: 4}},
+		Tags:    []string{"a"},
+	}
+
+	res, err := Project(u, "name,address{city,zip},orders[*]{id},Tags")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":    "bob",
+		"address": map[string]interface{}{"city": "Paris", "zip": "75001"},
+		"orders":  []interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": 3}},
+		"Tags":    []string{"a"},
+	}, res)
+
+	res, err = Project(projUser{Name: "bob"}, "Name,address{city},orders[*]{ID},tags[*]")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]interface{}{"name": "bob", "address": nil, "orders": nil, "Tags": nil}, res)
+
+	_, err = Project(nil, "name")
+	assert.EqualError(t, err, "obj must not be nil")
+	_, err = Project(1, "name")
+	assert.EqualError(t, err, "obj must be struct")
+	_, err = Project(u, "")
+	assert.EqualError(t, err, "fields must not be empty")
+	_, err = Project(u, "name,")
+	assert.EqualError(t, err, "fields: name, is invalid: unexpected end")
+	_, err = Project(u, "address{city")
+	assert.EqualError(t, err, "fields: address{city is invalid: unexpected end")
+	_, err = Project(u, "orders[0]")
+	assert.EqualError(t, err, `fields: orders[0] is invalid: unexpected '[' at 6`)
+	_, err = Project(u, "name}")
+	assert.EqualError(t, err, `fields: name} is invalid: unexpected '}' at 4`)
+	_, err = Project(u, "address{country}")
+	assert.EqualError(t, err, "no such field: country")
+	_, err = Project(u, "Hash")
+	assert.EqualError(t, err, "no such field: Hash")
+	_, err = Project(u, "hash")
+	assert.EqualError(t, err, "no such field: hash")
+	_, err = Project(u, "secret")
+	assert.EqualError(t, err, "field: secret is unexported")
+	_, err = Project(u, "name[*]")
+	assert.EqualError(t, err, "field: Name is not slice")
+	_, err = Project(u, "orders{id}")
+	assert.EqualError(t, err, "field: Orders is not struct")
+
+	res, err = Project((*projUser)(nil), "name")
+	assert.Equal(t, nil, err)
+	assert.Nil(t, res)
+	_, err = Project((*projUser)(nil), "country")
+	assert.EqualError(t, err, "no such field: country")
+}
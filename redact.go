@@ -0,0 +1,251 @@
+package xreflect
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RedactMode is the way a sensitive string is masked by Redact.
+type RedactMode int
+
+const (
+	// RedactFull replaces the whole string with the mask.
+	RedactFull RedactMode = iota
+	// RedactKeepLast replaces all but the last RedactOptions.KeepLast characters with the mask.
+	RedactKeepLast
+	// RedactHash replaces the string with a short SHA-256 hash, so that equal values can still be correlated.
+	RedactHash
+)
+
+// RedactOptions configures Redact. The zero value masks the fields tagged `sensitive:"true"` with "***".
+type RedactOptions struct {
+	// TagKey is the tag marking sensitive fields, "sensitive" by default.
+	TagKey string
+	// Paths are patterns of sensitive paths such as "User.Password" or "Cards[*].Number",
+	// where * matches any single field name, map key or index.
+	Paths []string
+	// Mode is the mask mode of fields tagged "true" and of paths.
+	Mode RedactMode
+	// KeepLast is the number of kept characters for RedactKeepLast.
+	KeepLast int
+	// Mask replaces masked characters, "***" by default.
+	Mask string
+}
+
+// Redact returns a deep copy of obj, of the same type, in which the sensitive values are masked, so that it can
+// be logged safely. The original is not modified. Nested structures, pointers, interfaces, slices, arrays and maps
+// are traversed, and a value is sensitive if its field has the tag `sensitive:"true"`, or `sensitive:"hash"` or
+// `sensitive:"last=4"` to choose the mode, or if its path matches one of opts.Paths.
+// Sensitive strings are masked, also inside pointers, slices, arrays and maps, other sensitive values are zeroed.
+func Redact(obj interface{}, opts RedactOptions) (interface{}, error) {
+	if obj == nil {
+		return nil, errors.New("obj must not be nil")
+	}
+	if opts.TagKey == "" {
+		opts.TagKey = "sensitive"
+	}
+	if opts.Mask == "" {
+		opts.Mask = "***"
+	}
+
+	r := &redactor{opts: opts, visited: make(map[visitKey]bool)}
+	for _, p := range opts.Paths {
+		r.patterns = append(r.patterns, pathPattern(p))
+	}
+
+	val := deepCopy(reflect.ValueOf(obj))
+	res := reflect.New(val.Type()).Elem()
+	res.Set(val)
+	if err := r.redact(res, ""); err != nil {
+		return nil, err
+	}
+	return res.Interface(), nil
+}
+
+// pathPattern converts a path pattern to a regexp, where * matches any single field name, map key or index.
+func pathPattern(pattern string) *regexp.Regexp {
+	expr := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, `[^.\[\]]*`)
+	return regexp.MustCompile("^" + expr + "$")
+}
+
+type redactRule struct {
+	mode     RedactMode
+	keepLast int
+}
+
+type redactor struct {
+	opts     RedactOptions
+	patterns []*regexp.Regexp
+	visited  map[visitKey]bool
+}
+
+// redact masks the sensitive values in the settable val.
+func (r *redactor) redact(val reflect.Value, path string) error {
+	if path != "" && r.matchPath(path) {
+		r.mask(val, redactRule{mode: r.opts.Mode, keepLast: r.opts.KeepLast})
+		return nil
+	}
+
+	switch val.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			return nil
+		}
+		if !r.visit(visitKey{ptr: val.Pointer(), typ: val.Type()}) {
+			return nil
+		}
+		return r.redact(val.Elem(), path)
+	case reflect.Interface:
+		if val.IsNil() {
+			return nil
+		}
+		elem := reflect.New(val.Elem().Type()).Elem()
+		elem.Set(val.Elem())
+		if err := r.redact(elem, path); err != nil {
+			return err
+		}
+		val.Set(elem)
+	case reflect.Struct:
+		typ := val.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+			fv := accessibleValue(val.Field(i))
+			rule, ok, err := r.tagRule(field)
+			if err != nil {
+				return fmt.Errorf("field: %s %w", fieldPath, err)
+			}
+			if ok {
+				r.mask(fv, rule)
+				continue
+			}
+			if err := r.redact(fv, fieldPath); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && !r.visit(visitKey{ptr: val.Pointer(), typ: val.Type(), len: val.Len()}) {
+			return nil
+		}
+		for i := 0; i < val.Len(); i++ {
+			if err := r.redact(val.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if !r.visit(visitKey{ptr: val.Pointer(), typ: val.Type()}) {
+			return nil
+		}
+		iter := val.MapRange()
+		for iter.Next() {
+			elem := reflect.New(val.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			if err := r.redact(elem, fmt.Sprintf("%s[%v]", path, iter.Key())); err != nil {
+				return err
+			}
+			val.SetMapIndex(iter.Key(), elem)
+		}
+	}
+	return nil
+}
+
+// visit marks the pointer, map or slice of key as visited, and reports whether it was not already.
+func (r *redactor) visit(key visitKey) bool {
+	if r.visited[key] {
+		return false
+	}
+	r.visited[key] = true
+	return true
+}
+
+func (r *redactor) matchPath(path string) bool {
+	for _, p := range r.patterns {
+		if p.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *redactor) tagRule(field reflect.StructField) (redactRule, bool, error) {
+	tag := field.Tag.Get(r.opts.TagKey)
+	switch {
+	case tag == "" || tag == "false":
+		return redactRule{}, false, nil
+	case tag == "true":
+		return redactRule{mode: r.opts.Mode, keepLast: r.opts.KeepLast}, true, nil
+	case tag == "hash":
+		return redactRule{mode: RedactHash}, true, nil
+	case strings.HasPrefix(tag, "last="):
+		n, err := strconv.Atoi(strings.TrimPrefix(tag, "last="))
+		if err != nil || n < 0 {
+			return redactRule{}, false, fmt.Errorf("has invalid %s tag: %s", r.opts.TagKey, tag)
+		}
+		return redactRule{mode: RedactKeepLast, keepLast: n}, true, nil
+	default:
+		return redactRule{}, false, fmt.Errorf("has invalid %s tag: %s", r.opts.TagKey, tag)
+	}
+}
+
+// mask masks the strings in the settable val, and zeroes other values.
+func (r *redactor) mask(val reflect.Value, rule redactRule) {
+	switch val.Kind() {
+	case reflect.String:
+		val.SetString(r.maskString(val.String(), rule))
+	case reflect.Ptr:
+		if !val.IsNil() && val.Elem().Kind() == reflect.String {
+			val.Elem().SetString(r.maskString(val.Elem().String(), rule))
+			return
+		}
+		val.Set(reflect.Zero(val.Type()))
+	case reflect.Slice, reflect.Array:
+		if val.Type().Elem().Kind() != reflect.String {
+			val.Set(reflect.Zero(val.Type()))
+			return
+		}
+		for i := 0; i < val.Len(); i++ {
+			r.mask(val.Index(i), rule)
+		}
+	case reflect.Map:
+		if val.Type().Elem().Kind() != reflect.String {
+			val.Set(reflect.Zero(val.Type()))
+			return
+		}
+		iter := val.MapRange()
+		for iter.Next() {
+			masked := reflect.New(val.Type().Elem()).Elem()
+			masked.SetString(r.maskString(iter.Value().String(), rule))
+			val.SetMapIndex(iter.Key(), masked)
+		}
+	default:
+		val.Set(reflect.Zero(val.Type()))
+	}
+}
+
+func (r *redactor) maskString(s string, rule redactRule) string {
+	if s == "" {
+		return s
+	}
+	switch rule.mode {
+	case RedactHash:
+		sum := sha256.Sum256([]byte(s))
+		return "sha256:" + hex.EncodeToString(sum[:8])
+	case RedactKeepLast:
+		runes := []rune(s)
+		if rule.keepLast <= 0 || len(runes) <= rule.keepLast {
+			return r.opts.Mask
+		}
+		return r.opts.Mask + string(runes[len(runes)-rule.keepLast:])
+	default:
+		return r.opts.Mask
+	}
+}
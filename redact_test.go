@@ -0,0 +1,95 @@
+package xreflect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type redactCard struct {
+	Number string `sensitive:"last=4"`
+	Holder string
+}
+
+type redactUser struct {
+	Name     string
+	Password string `sensitive:"true"`
+	Email    string `sensitive:"hash"`
+	PIN      int    `sensitive:"true"`
+	Token    *string
+	Cards    []redactCard
+	Headers  map[string]string
+	Extra    interface{}
+	Self     *redactUser
+	secret   string `sensitive:"true"`
+}
+
+func TestRedact(t *testing.T) {
+	_, err := Redact(nil, RedactOptions{})
+	assert.EqualError(t, err, "obj must not be nil")
+
+	token := "tok-123"
+	u := &redactUser{
+		Name:     "bob",
+		Password: "hunter2",
+		Email:    "bob@example.com",
+		PIN:      1234,
+		Token:    &token,
+		Cards:    []redactCard{{Number: "4111111111111111", Holder: "bob"}, {Number: "123"}},
+		Headers:  map[string]string{"Authorization": "Bearer x", "Accept": "*/*"},
+		Extra:    redactCard{Number: "5555000011112222"},
+		secret:   "s",
+	}
+	u.Self = u
+
+	res, err := Redact(u, RedactOptions{Paths: []string{"Token", "Headers[Authorization]", "Cards[*].Holder"}})
+	assert.Equal(t, nil, err)
+	r := res.(*redactUser)
+	assert.Equal(t, "bob", r.Name)
+	assert.Equal(t, "***", r.Password)
+	assert.Equal(t, "sha256:5ff860bf1190596c", r.Email)
+	assert.Equal(t, 0, r.PIN)
+	assert.Equal(t, "***", *r.Token)
+	assert.Equal(t, []redactCard{{Number: "***1111", Holder: "***"}, {Number: "***"}}, r.Cards)
+	assert.Equal(t, map[string]string{"Authorization": "***", "Accept": "*/*"}, r.Headers)
+	assert.Equal(t, redactCard{Number: "***2222"}, r.Extra)
+	assert.Equal(t, "***", r.secret)
+	assert.Same(t, r, r.Self)
+
+	assert.Equal(t, "hunter2", u.Password)
+	assert.Equal(t, "tok-123", token)
+	assert.Equal(t, "4111111111111111", u.Cards[0].Number)
+	assert.Equal(t, "Bearer x", u.Headers["Authorization"])
+	assert.Equal(t, "s", u.secret)
+
+	res, err = Redact(*u, RedactOptions{Mode: RedactKeepLast, KeepLast: 2, Mask: "#", Paths: []string{"*"}})
+	assert.Equal(t, nil, err)
+	v := res.(redactUser)
+	assert.Equal(t, "#ob", v.Name)
+	assert.Equal(t, "#r2", v.Password)
+	assert.Nil(t, v.Cards)
+
+	type pii struct {
+		Phone string `pii:"yes"`
+	}
+	_, err = Redact(pii{}, RedactOptions{TagKey: "pii"})
+	assert.EqualError(t, err, "field: Phone has invalid pii tag: yes")
+}
+
+func TestRedactCycle(t *testing.T) {
+	items := []interface{}{nil, 1}
+	items[0] = items
+	m := map[string]interface{}{}
+	m["self"] = m
+
+	type redactLoop struct {
+		Items []interface{}
+		Meta  map[string]interface{}
+		Token string `sensitive:"true"`
+	}
+	loop := &redactLoop{Items: items, Meta: m, Token: "secret"}
+	res, err := Redact(loop, RedactOptions{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "secret", loop.Token)
+	assert.Equal(t, "***", res.(*redactLoop).Token)
+}
@@ -0,0 +1,327 @@
+package xreflect
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Registry is a set of functions and methods registered under names, which can be invoked by name with
+// Go values, raw strings or JSON arguments. It is safe for concurrent use.
+// Calls are routed through CallFunc and CallFuncSlice, so the variadic parameters and the trailing error
+// are handled in the same way.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]*registryEntry
+}
+
+type registryEntry struct {
+	fn     reflect.Value
+	params []string
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]*registryEntry)}
+}
+
+// Register registers fn under name. The optional paramNames are the names of the parameters of fn,
+// which are required to call it by CallJSON with a JSON object and shown by Signature.
+// The signature of fn is validated: its parameters must be decodable from strings or JSON, i.e. not chan,
+// func or unsafe pointer, and an error result, if any, must be the last one.
+func (r *Registry) Register(name string, fn interface{}, paramNames ...string) error {
+	entry, err := newRegistryEntry(name, fn, paramNames)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entries[name]; ok {
+		return fmt.Errorf("name: %s is already registered", name)
+	}
+	r.entries[name] = entry
+	return nil
+}
+
+// RegisterMethods registers all exported methods of obj under the names "prefix.Method", using the method set
+// of obj, so methods with a pointer receiver are only registered if obj is a pointer.
+// Parameter names registered by RegisterMethodParams are used. Nothing is registered if any method
+// has an invalid signature or a name is already registered.
+func (r *Registry) RegisterMethods(prefix string, obj interface{}) error {
+	if obj == nil {
+		return errors.New("obj must not be nil")
+	}
+	if prefix == "" {
+		return errors.New("prefix must not be empty")
+	}
+
+	isPtr := reflect.TypeOf(obj).Kind() == reflect.Pointer
+	val := reflect.ValueOf(obj)
+	entries := make(map[string]*registryEntry)
+	var err error
+	rangeErr := RangeMethods(obj, func(i int, m MethodInfo) bool {
+		if m.PointerReceiver && !isPtr {
+			return true
+		}
+		paramNamesMu.RLock()
+		names := methodParamNames[methodKey{Type(obj), m.Name}]
+		paramNamesMu.RUnlock()
+
+		name := prefix + "." + m.Name
+		entries[name], err = newRegistryEntry(name, val.MethodByName(m.Name).Interface(), names)
+		return err == nil
+	})
+	if rangeErr != nil {
+		return rangeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range entries {
+		if _, ok := r.entries[name]; ok {
+			return fmt.Errorf("name: %s is already registered", name)
+		}
+	}
+	for name, entry := range entries {
+		r.entries[name] = entry
+	}
+	return nil
+}
+
+// Names returns the sorted names of all registered functions.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Signature returns the signature of the function registered under name, including the parameter names
+// if known, e.g. "func(name string, times ...int) error".
+func (r *Registry) Signature(name string) (string, error) {
+	entry, err := r.entry(name)
+	if err != nil {
+		return "", err
+	}
+	if entry.params == nil {
+		return methodTypeString(entry.fn.Type(), false), nil
+	}
+
+	typ := entry.fn.Type()
+	in := make([]string, typ.NumIn())
+	for i := range in {
+		if typ.IsVariadic() && i == typ.NumIn()-1 {
+			in[i] = entry.params[i] + " ..." + typ.In(i).Elem().String()
+			continue
+		}
+		in[i] = entry.params[i] + " " + typ.In(i).String()
+	}
+	out := make([]reflect.Type, typ.NumOut())
+	for i := range out {
+		out[i] = typ.Out(i)
+	}
+	results := strings.TrimPrefix(methodTypeString(reflect.FuncOf(nil, out, false), false), "func()")
+	return "func(" + strings.Join(in, ", ") + ")" + results, nil
+}
+
+// Call calls the function registered under name with args, see CallFunc for more details.
+func (r *Registry) Call(name string, args ...interface{}) ([]reflect.Value, error) {
+	entry, err := r.entry(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return CallFunc(entry.fn, args...)
+}
+
+// CallStrings calls the function registered under name with raw string arguments, e.g. from a command line.
+// Each argument is parsed according to its parameter type: strings are used as is, numbers and bools are parsed,
+// and other types are decoded as JSON.
+func (r *Registry) CallStrings(name string, args ...string) ([]reflect.Value, error) {
+	entry, err := r.entry(name)
+	if err != nil {
+		return nil, err
+	}
+
+	typ := entry.fn.Type()
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		v, err := parseStringArg(arg, paramType(typ, i))
+		if err != nil {
+			return nil, fmt.Errorf("fn param %d: %w", i, err)
+		}
+		values[i] = v
+	}
+	return CallFunc(entry.fn, values...)
+}
+
+// CallJSON calls the function registered under name with JSON arguments, which are either an array of
+// positional arguments, or an object of named arguments if the parameter names are registered. A missing named
+// argument is passed as the zero value, and the value of a named variadic parameter must be an array.
+func (r *Registry) CallJSON(name string, data []byte) ([]reflect.Value, error) {
+	entry, err := r.entry(name)
+	if err != nil {
+		return nil, err
+	}
+
+	typ := entry.fn.Type()
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		return entry.callJSONObject(data)
+	}
+
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, fmt.Errorf("args must be JSON array or object: %w", err)
+	}
+	values := make([]interface{}, len(raws))
+	for i, raw := range raws {
+		v, err := decodeJSONArg(raw, paramType(typ, i))
+		if err != nil {
+			return nil, fmt.Errorf("fn param %d: %w", i, err)
+		}
+		values[i] = v
+	}
+	return CallFunc(entry.fn, values...)
+}
+
+func (e *registryEntry) callJSONObject(data []byte) ([]reflect.Value, error) {
+	if e.params == nil {
+		return nil, errors.New("param names are not registered, args must be JSON array")
+	}
+
+	var raws map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, err
+	}
+	typ := e.fn.Type()
+	values := make([]interface{}, typ.NumIn())
+	for i := range values {
+		values[i] = reflect.Zero(typ.In(i)).Interface()
+	}
+	var unknown []string
+	for key, raw := range raws {
+		i := paramIndex(e.params, key)
+		if i < 0 {
+			unknown = append(unknown, key)
+			continue
+		}
+		v, err := decodeJSONArg(raw, typ.In(i))
+		if err != nil {
+			return nil, fmt.Errorf("fn param %s: %w", e.params[i], err)
+		}
+		values[i] = v
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown params: %s", strings.Join(unknown, ", "))
+	}
+
+	if typ.IsVariadic() {
+		return CallFuncSlice(e.fn, values...)
+	}
+	return CallFunc(e.fn, values...)
+}
+
+func (r *Registry) entry(name string) (*registryEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.entries[name]
+	if !ok {
+		return nil, fmt.Errorf("name: %s not found", name)
+	}
+	return entry, nil
+}
+
+func newRegistryEntry(name string, fn interface{}, params []string) (*registryEntry, error) {
+	if name == "" {
+		return nil, errors.New("name must not be empty")
+	}
+	if fn == nil {
+		return nil, errors.New("fn must not be nil")
+	}
+	val := reflect.ValueOf(fn)
+	if val.Kind() != reflect.Func || val.IsNil() {
+		return nil, fmt.Errorf("%s: fn must be non-nil func", name)
+	}
+
+	typ := val.Type()
+	for i := 0; i < typ.NumIn(); i++ {
+		in := paramType(typ, i)
+		if isSupportedKind(in.Kind(), []reflect.Kind{reflect.Chan, reflect.Func, reflect.UnsafePointer}) {
+			return nil, fmt.Errorf("%s: fn param %d has unsupported type %s", name, i, typ.In(i))
+		}
+	}
+	for i := 0; i < typ.NumOut()-1; i++ {
+		if typ.Out(i) == errorType {
+			return nil, fmt.Errorf("%s: fn error result must be the last one", name)
+		}
+	}
+	if len(params) > 0 {
+		if err := checkParamNames(typ, 0, params); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	} else {
+		params = nil
+	}
+
+	return &registryEntry{fn: val, params: params}, nil
+}
+
+// paramType returns the type of the i-th argument of the func type typ, the element type for variadic arguments.
+func paramType(typ reflect.Type, i int) reflect.Type {
+	if typ.IsVariadic() && i >= typ.NumIn()-1 {
+		return typ.In(typ.NumIn() - 1).Elem()
+	}
+	if i >= typ.NumIn() {
+		return nil
+	}
+	return typ.In(i)
+}
+
+func parseStringArg(arg string, typ reflect.Type) (interface{}, error) {
+	if typ == nil {
+		return arg, nil
+	}
+	if typ.Kind() == reflect.String || typ.Kind() == reflect.Interface {
+		return arg, nil
+	}
+	base := typ
+	for base.Kind() == reflect.Pointer {
+		base = base.Elem()
+	}
+	if isNumberKind(base.Kind()) || base.Kind() == reflect.Bool || base.Kind() == reflect.String {
+		v, err := convertValue(reflect.ValueOf(arg), typ)
+		if err != nil {
+			return nil, err
+		}
+		return v.Interface(), nil
+	}
+	return decodeJSONArg([]byte(arg), typ)
+}
+
+func decodeJSONArg(raw []byte, typ reflect.Type) (interface{}, error) {
+	if typ == nil {
+		return nil, nil
+	}
+	ptr := reflect.New(typ)
+	if err := json.Unmarshal(raw, ptr.Interface()); err != nil {
+		return nil, fmt.Errorf("cannot decode %s as %s: %w", raw, typ, err)
+	}
+	return ptr.Elem().Interface(), nil
+}
@@ -0,0 +1,160 @@
+package xreflect
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type UserService struct {
+	users map[string]int
+}
+
+type NewUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func (s *UserService) Create(u NewUser) (int, error) {
+	if u.Name == "" {
+		return 0, errors.New("name is required")
+	}
+	s.users[u.Name] = u.Age
+	return len(s.users), nil
+}
+
+func (s *UserService) Age(name string) int {
+	return s.users[name]
+}
+
+func (s UserService) Count() int {
+	return len(s.users)
+}
+
+func TestRegistryRegister(t *testing.T) {
+	reg := NewRegistry()
+	err := reg.Register("", addFunc)
+	assert.EqualError(t, err, "name must not be empty")
+
+	err = reg.Register("add", nil)
+	assert.EqualError(t, err, "fn must not be nil")
+
+	err = reg.Register("add", 1)
+	assert.EqualError(t, err, "add: fn must be non-nil func")
+
+	err = reg.Register("chan", func(c chan int) {})
+	assert.EqualError(t, err, "chan: fn param 0 has unsupported type chan int")
+
+	err = reg.Register("err", func() (error, int) { return nil, 0 })
+	assert.EqualError(t, err, "err: fn error result must be the last one")
+
+	err = reg.Register("add", addFunc, "a")
+	assert.EqualError(t, err, "add: fn params num is 2, but got 1 names")
+
+	err = reg.Register("add", addFunc, "a", "b")
+	assert.Equal(t, nil, err)
+
+	err = reg.Register("add", addFunc)
+	assert.EqualError(t, err, "name: add is already registered")
+
+	err = reg.RegisterMethods("", &UserService{})
+	assert.EqualError(t, err, "prefix must not be empty")
+
+	err = reg.RegisterMethods("user", nil)
+	assert.EqualError(t, err, "obj must not be nil")
+
+	err = reg.RegisterMethods("user", UserService{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"add", "user.Count"}, reg.Names())
+
+	err = reg.RegisterMethods("user", &UserService{})
+	assert.EqualError(t, err, "name: user.Count is already registered")
+	assert.Equal(t, []string{"add", "user.Count"}, reg.Names())
+
+	err = reg.RegisterMethods("users", &UserService{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"add", "user.Count", "users.Age", "users.Count", "users.Create"}, reg.Names())
+
+	s, err := reg.Signature("add")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "func(a int, b int) int", s)
+
+	s, err = reg.Signature("users.Create")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "func(xreflect.NewUser) (int, error)", s)
+
+	_, err = reg.Signature("sub")
+	assert.EqualError(t, err, "name: sub not found")
+}
+
+func TestRegistryCall(t *testing.T) {
+	reg := NewRegistry()
+	svc := &UserService{users: map[string]int{}}
+	err := RegisterMethodParams(svc, "Age", "name")
+	assert.Equal(t, nil, err)
+	err = reg.RegisterMethods("user", svc)
+	assert.Equal(t, nil, err)
+	err = reg.Register("greet", greetFunc, "name", "times", "suffixes")
+	assert.Equal(t, nil, err)
+
+	s, err := reg.Signature("greet")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "func(name string, times int, suffixes ...string) string", s)
+
+	_, err = reg.Call("sub", 1)
+	assert.EqualError(t, err, "name: sub not found")
+
+	res, err := reg.Call("user.Create", NewUser{Name: "bob", Age: 20})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, res[0].Interface())
+
+	_, err = reg.Call("user.Create", NewUser{})
+	assert.EqualError(t, err, "name is required")
+
+	res, err = reg.CallJSON("user.Create", []byte(`[{"name": "alice", "age": 30}]`))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, res[0].Interface())
+
+	res, err = reg.CallStrings("user.Create", `{"name": "carol", "age": 40}`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, res[0].Interface())
+
+	res, err = reg.CallStrings("user.Age", "carol")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 40, res[0].Interface())
+
+	res, err = reg.CallJSON("user.Age", []byte(` {"name": "alice"}`))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 30, res[0].Interface())
+
+	_, err = reg.CallJSON("user.Create", []byte(`{"u": {}}`))
+	assert.EqualError(t, err, "param names are not registered, args must be JSON array")
+
+	_, err = reg.CallJSON("user.Age", []byte(`{"id": 1}`))
+	assert.EqualError(t, err, "unknown params: id")
+
+	_, err = reg.CallJSON("user.Age", []byte(`1`))
+	assert.ErrorContains(t, err, "args must be JSON array or object: ")
+
+	_, err = reg.CallJSON("user.Age", []byte(`[1]`))
+	assert.ErrorContains(t, err, "fn param 0: cannot decode 1 as string: ")
+
+	_, err = reg.CallJSON("user.Age", []byte(`["a", "b"]`))
+	assert.EqualError(t, err, "fn params num is 1, but got 2")
+
+	res, err = reg.CallStrings("greet", "bob", "2", "!", "?")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hi bobhi bob!?", res[0].Interface())
+
+	_, err = reg.CallStrings("greet", "bob", "x")
+	assert.EqualError(t, err, "fn param 1: cannot use string \"x\" as int")
+
+	res, err = reg.CallJSON("greet", []byte(`["bob", 1, "!", "?"]`))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hi bob!?", res[0].Interface())
+
+	res, err = reg.CallJSON("greet", []byte(`{"name": "bob", "times": 1, "suffixes": ["!"]}`))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hi bob!", res[0].Interface())
+}
@@ -0,0 +1,73 @@
+package xreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// SetFields sets several fields of obj at once, with paths like SetEmbedField, e.g. "Address.City", or json
+// tag names. All paths and value conversions are validated before obj is modified, and if an assignment still
+// fails, the fields set so far are restored, including the nil pointers to intermediate structures allocated
+// on the way, so obj is never half-updated. The values are deep copied, so obj does not share them with the
+// caller. The fields are set in the order of their position in obj, so a structure is set before its nested
+// fields, e.g. "Address" before "Address.City", and two paths selecting the same field are rejected.
+// The obj must be a pointer to a structure.
+func SetFields(obj interface{}, values map[string]interface{}) error {
+	if obj == nil {
+		return errors.New("obj must not be nil")
+	}
+	if reflect.TypeOf(obj).Kind() != reflect.Ptr || Type(obj).Kind() != reflect.Struct {
+		return errors.New("obj must be struct pointer")
+	}
+
+	paths := make([]string, 0, len(values))
+	for path := range values {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	typ := Type(obj)
+	val := Value(obj)
+	writes := make([]bindWrite, 0, len(values))
+	keys := make(map[string]string, len(values))
+	for _, path := range paths {
+		goPath, index, err := goFieldPath(typ, path, "json")
+		if err != nil {
+			return err
+		}
+		indexKey := fmt.Sprint(index)
+		if key, ok := keys[indexKey]; ok {
+			return fmt.Errorf("fields: %s and %s are the same field", key, path)
+		}
+		keys[indexKey] = path
+		if err := checkFieldIndex(val, index); err != nil {
+			return err
+		}
+		field, err := EmbedStructField(typ, goPath)
+		if err != nil {
+			return err
+		}
+		v, err := convertValue(reflect.ValueOf(values[path]), field.Type)
+		if err != nil {
+			return fmt.Errorf("field: %s: %w", path, err)
+		}
+		writes = append(writes, bindWrite{path: goPath, index: index, value: deepCopy(v)})
+	}
+	// A structure is set before its nested fields, which are then set on top of it.
+	sort.Slice(writes, func(i, j int) bool {
+		return lessIndex(writes[i].index, writes[j].index)
+	})
+	return setFieldsWithUndo(val, writes)
+}
+
+// lessIndex reports whether the field at index a comes before the field at index b, parents first.
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
@@ -0,0 +1,81 @@
+package xreflect
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type setFieldsInner struct {
+	X int
+}
+
+type setFieldsPlain struct {
+	setFieldsInner
+	Name string
+}
+
+type setFieldsOuter struct {
+	Name    string       `json:"name"`
+	Age     int          `json:"age"`
+	Address *maskAddress `json:"address"`
+	Home    maskAddress
+	*setFieldsInner
+}
+
+func TestSetFields(t *testing.T) {
+	assert.EqualError(t, SetFields(nil, nil), "obj must not be nil")
+	assert.EqualError(t, SetFields(setFieldsOuter{}, nil), "obj must be struct pointer")
+
+	o := &setFieldsOuter{Name: "a", setFieldsInner: &setFieldsInner{}}
+	assert.Equal(t, nil, SetFields(o, map[string]interface{}{
+		"name":         "b",
+		"Age":          "3",
+		"address.city": "Paris",
+		"Home.Zip":     "75001",
+		"X":            1,
+	}))
+	assert.Equal(t, &setFieldsOuter{
+		Name:           "b",
+		Age:            3,
+		Address:        &maskAddress{City: "Paris"},
+		Home:           maskAddress{Zip: "75001"},
+		setFieldsInner: &setFieldsInner{X: 1},
+	}, o)
+
+	assert.EqualError(t, SetFields(o, map[string]interface{}{"name": "c", "age": "x"}),
+		`field: age: cannot use string "x" as int`)
+	assert.EqualError(t, SetFields(o, map[string]interface{}{"name": "c", "nope": 1}), "no such field: nope")
+	assert.EqualError(t, SetFields(o, map[string]interface{}{"name": "c", "Name.X": 1}), "field: Name is not struct")
+	assert.Equal(t, "b", o.Name)
+
+	o = &setFieldsOuter{Name: "a", Home: maskAddress{City: "Rome"}}
+	err := SetFields(o, map[string]interface{}{"Address.Zip": "1", "Name": "b", "X": 1})
+	assert.EqualError(t, err, "field: setFieldsInner can not set")
+	assert.EqualError(t, SetFields(o, map[string]interface{}{"name": "b", "Name": "c"}),
+		"fields: Name and name are the same field")
+	assert.Equal(t, &setFieldsOuter{Name: "a", Home: maskAddress{City: "Rome"}}, o)
+
+	// a failing write restores the fields set before it
+	err = setFieldsWithUndo(Value(o), []bindWrite{
+		{path: "Address.Zip", index: []int{2, 1}, value: reflect.ValueOf("1")},
+		{path: "Home.City", index: []int{3, 0}, value: reflect.ValueOf("Oslo")},
+		{path: "Name", index: []int{0}, value: reflect.ValueOf([]int{1})},
+	})
+	assert.Contains(t, err.Error(), "field: Name: ")
+	assert.Equal(t, &setFieldsOuter{Name: "a", Home: maskAddress{City: "Rome"}}, o)
+
+	addr := &maskAddress{City: "a"}
+	assert.Equal(t, nil, SetFields(o, map[string]interface{}{"address": addr, "Address.Zip": "z"}))
+	assert.Equal(t, &maskAddress{City: "a", Zip: "z"}, o.Address)
+	assert.Equal(t, &maskAddress{City: "a"}, addr)
+
+	p := &setFieldsPlain{}
+	assert.Equal(t, nil, SetFields(p, map[string]interface{}{"X": 5, "Name": "a"}))
+	assert.Equal(t, &setFieldsPlain{setFieldsInner: setFieldsInner{X: 5}, Name: "a"}, p)
+
+	acc := &maskAccount{}
+	assert.Equal(t, nil, SetFields(acc, map[string]interface{}{"id": 1, "home.city": "a"}))
+	assert.Equal(t, &MaskBase{ID: 1, Home: &maskAddress{City: "a"}}, acc.MaskBase)
+}
@@ -0,0 +1,144 @@
+package xreflect
+
+import (
+	"errors"
+	"reflect"
+)
+
+// SizeReport is the result of DeepSizeOf.
+type SizeReport struct {
+	// Total is the number of bytes of the value and of everything reachable from it.
+	Total uintptr
+	// Paths maps the path of each structure field, such as "Cache.Entries", to the number of bytes of the field
+	// and of everything first reached through it. The fields of elements of slices, arrays and maps are not
+	// listed separately, they are included in the container field.
+	Paths map[string]uintptr
+}
+
+// DeepSizeOf estimates the memory used by obj and everything reachable from it: the size of the value itself,
+// the bytes of strings, the backing arrays of slices by capacity, approximate map buckets, and the values pointed
+// to, each counted once even if it is shared. If obj is a pointer, the pointer itself is not counted.
+func DeepSizeOf(obj interface{}) (*SizeReport, error) {
+	if obj == nil {
+		return nil, errors.New("obj must not be nil")
+	}
+	val := reflect.ValueOf(obj)
+	if v, ok := obj.(reflect.Value); ok {
+		val = v
+	}
+
+	s := &sizer{
+		report: &SizeReport{Paths: make(map[string]uintptr)},
+		seen:   make(map[visitKey]bool),
+	}
+	if val.Kind() == reflect.Ptr {
+		s.report.Total = s.heapSize(val, "", true)
+	} else {
+		s.report.Total = val.Type().Size() + s.heapSize(val, "", true)
+	}
+	return s.report, nil
+}
+
+type sizer struct {
+	report *SizeReport
+	seen   map[visitKey]bool
+}
+
+// visit reports whether the memory referred to by val is seen for the first time.
+func (s *sizer) visit(val reflect.Value) bool {
+	key := visitKey{ptr: val.Pointer(), typ: val.Type()}
+	if s.seen[key] {
+		return false
+	}
+	s.seen[key] = true
+	return true
+}
+
+// heapSize returns the number of bytes reachable from val, without the size of val itself.
+// If record is true, the fields of structures are recorded in the report under path.
+func (s *sizer) heapSize(val reflect.Value, path string, record bool) uintptr {
+	switch val.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() || !s.visit(val) {
+			return 0
+		}
+		return val.Type().Elem().Size() + s.heapSize(val.Elem(), path, record)
+	case reflect.Interface:
+		if val.IsNil() {
+			return 0
+		}
+		elem := val.Elem()
+		switch elem.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			// stored directly in the interface
+			return s.heapSize(elem, path, record)
+		default:
+			return elem.Type().Size() + s.heapSize(elem, path, record)
+		}
+	case reflect.String:
+		return uintptr(val.Len())
+	case reflect.Slice:
+		if val.IsNil() || !s.visit(val) {
+			return 0
+		}
+		n := uintptr(val.Cap()) * val.Type().Elem().Size()
+		for i := 0; i < val.Len(); i++ {
+			n += s.heapSize(val.Index(i), "", false)
+		}
+		return n
+	case reflect.Array:
+		var n uintptr
+		for i := 0; i < val.Len(); i++ {
+			n += s.heapSize(val.Index(i), "", false)
+		}
+		return n
+	case reflect.Map:
+		if val.IsNil() || !s.visit(val) {
+			return 0
+		}
+		n := mapOverhead(val.Type(), val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			n += s.heapSize(iter.Key(), "", false) + s.heapSize(iter.Value(), "", false)
+		}
+		return n
+	case reflect.Chan:
+		if val.IsNil() || !s.visit(val) {
+			return 0
+		}
+		return uintptr(val.Cap()) * val.Type().Elem().Size()
+	case reflect.Struct:
+		var n uintptr
+		typ := val.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+			fn := s.heapSize(val.Field(i), fieldPath, record)
+			if record {
+				s.report.Paths[fieldPath] = field.Type.Size() + fn
+			}
+			n += fn
+		}
+		return n
+	default:
+		return 0
+	}
+}
+
+// mapOverhead approximates the memory used by the header and buckets of a map with n entries,
+// assuming buckets of 8 entries with an average load of 6.5 entries.
+func mapOverhead(typ reflect.Type, n int) uintptr {
+	const (
+		headerSize    = 48
+		bucketEntries = 8
+	)
+	buckets := uintptr(1)
+	for float64(buckets)*6.5 < float64(n) {
+		buckets <<= 1
+	}
+	bucketSize := bucketEntries + bucketEntries*(typ.Key().Size()+typ.Elem().Size()) + reflect.TypeOf(uintptr(0)).Size()
+	return headerSize + buckets*bucketSize
+}
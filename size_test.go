@@ -0,0 +1,68 @@
+package xreflect
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sizeMeta struct {
+	Tag string
+}
+
+type sizeEntry struct {
+	Key    string
+	Values []int64
+	Meta   *sizeMeta
+	Shared *sizeMeta
+	Index  map[string]int
+	Any    interface{}
+	Next   *sizeEntry
+}
+
+func TestDeepSizeOf(t *testing.T) {
+	_, err := DeepSizeOf(nil)
+	assert.EqualError(t, err, "obj must not be nil")
+
+	r, err := DeepSizeOf(int64(1))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uintptr(8), r.Total)
+
+	r, _ = DeepSizeOf("abc")
+	assert.Equal(t, unsafe.Sizeof("")+3, r.Total)
+
+	r, _ = DeepSizeOf(make([]int32, 2, 10))
+	assert.Equal(t, unsafe.Sizeof([]int32{})+40, r.Total)
+
+	meta := &sizeMeta{Tag: "abcd"}
+	metaSize := unsafe.Sizeof(sizeMeta{}) + 4
+	e := &sizeEntry{
+		Key:    "key",
+		Values: make([]int64, 1, 4),
+		Meta:   meta,
+		Shared: meta,
+		Any:    int64(1),
+	}
+	e.Next = e
+
+	r, err = DeepSizeOf(e)
+	assert.Equal(t, nil, err)
+	entrySize := unsafe.Sizeof(sizeEntry{})
+	assert.Equal(t, entrySize+3+32+metaSize+8, r.Total)
+	assert.Equal(t, unsafe.Sizeof("")+3, r.Paths["Key"])
+	assert.Equal(t, unsafe.Sizeof([]int64{})+32, r.Paths["Values"])
+	assert.Equal(t, unsafe.Sizeof(meta)+metaSize, r.Paths["Meta"])
+	assert.Equal(t, unsafe.Sizeof("")+4, r.Paths["Meta.Tag"])
+	assert.Equal(t, unsafe.Sizeof(meta), r.Paths["Shared"])
+	assert.Equal(t, unsafe.Sizeof(e.Any)+8, r.Paths["Any"])
+	assert.Equal(t, unsafe.Sizeof(e), r.Paths["Next"])
+
+	e.Index = map[string]int{"a": 1, "bb": 2}
+	r, _ = DeepSizeOf(*e)
+	index := r.Paths["Index"] - unsafe.Sizeof(e.Index)
+	assert.Equal(t, true, index > 3)
+	assert.Equal(t, mapOverhead(reflect.TypeOf(e.Index), 2)+3, index)
+	assert.Equal(t, true, r.Paths["Next"] > entrySize)
+}
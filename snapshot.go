@@ -0,0 +1,56 @@
+package xreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// StructSnapshot is a deep copy of a structure taken by Snapshot, to find or undo the later changes.
+type StructSnapshot struct {
+	value reflect.Value
+}
+
+// Snapshot returns a snapshot of obj, a deep copy which is not affected by later changes to obj.
+// The obj must be a pointer to a structure.
+func Snapshot(obj interface{}) (*StructSnapshot, error) {
+	if err := checkSnapshotObj(obj, nil); err != nil {
+		return nil, err
+	}
+	return &StructSnapshot{value: deepCopy(Value(obj))}, nil
+}
+
+// Changed returns the paths of the fields of obj which differ from the snapshot, like FieldMaskFromDiff,
+// e.g. to update only the changed columns of a row.
+func (s *StructSnapshot) Changed(obj interface{}) ([]string, error) {
+	if err := checkSnapshotObj(obj, s.value.Type()); err != nil {
+		return nil, err
+	}
+	return FieldMaskFromDiff(s.value, obj)
+}
+
+// Restore sets obj back to the state of the snapshot, including unexported fields.
+// The snapshot is not affected and can be restored again.
+func (s *StructSnapshot) Restore(obj interface{}) error {
+	if err := checkSnapshotObj(obj, s.value.Type()); err != nil {
+		return err
+	}
+	Value(obj).Set(deepCopy(s.value))
+	return nil
+}
+
+func checkSnapshotObj(obj interface{}, typ reflect.Type) error {
+	if obj == nil {
+		return errors.New("obj must not be nil")
+	}
+	if reflect.TypeOf(obj).Kind() != reflect.Ptr || Type(obj).Kind() != reflect.Struct {
+		return errors.New("obj must be struct pointer")
+	}
+	if reflect.ValueOf(obj).IsNil() {
+		return errors.New("obj must not be nil")
+	}
+	if typ != nil && Type(obj) != typ {
+		return fmt.Errorf("obj must be *%s", typ)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package xreflect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type snapRow struct {
+	ID      int          `json:"id"`
+	Name    string       `json:"name"`
+	Tags    []string     `json:"tags"`
+	Address *maskAddress `json:"address"`
+	version int
+}
+
+func TestSnapshot(t *testing.T) {
+	_, err := Snapshot(nil)
+	assert.EqualError(t, err, "obj must not be nil")
+	_, err = Snapshot(snapRow{})
+	assert.EqualError(t, err, "obj must be struct pointer")
+	_, err = Snapshot((*snapRow)(nil))
+	assert.EqualError(t, err, "obj must not be nil")
+
+	row := &snapRow{ID: 1, Name: "a", Tags: []string{"x"}, Address: &maskAddress{City: "c"}, version: 1}
+	snap, err := Snapshot(row)
+	assert.Equal(t, nil, err)
+
+	changed, err := snap.Changed(row)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(changed))
+
+	row.Name = "b"
+	row.Tags[0] = "y"
+	row.Address.Zip = "z"
+	row.version = 2
+	changed, err = snap.Changed(row)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"name", "tags", "address.zip"}, changed)
+
+	_, err = snap.Changed(&maskAddress{})
+	assert.EqualError(t, err, "obj must be *xreflect.snapRow")
+	assert.EqualError(t, snap.Restore(&maskAddress{}), "obj must be *xreflect.snapRow")
+
+	assert.Equal(t, nil, snap.Restore(row))
+	assert.Equal(t, &snapRow{ID: 1, Name: "a", Tags: []string{"x"}, Address: &maskAddress{City: "c"}, version: 1}, row)
+
+	row.Tags[0] = "z"
+	other := &snapRow{}
+	assert.Equal(t, nil, snap.Restore(other))
+	assert.Equal(t, []string{"x"}, other.Tags)
+	assert.Equal(t, 1, other.version)
+}
@@ -0,0 +1,427 @@
+package xreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Validator can be implemented by the types of nested values to add checks which cannot be expressed by tags.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationRule checks value against the param of a `validate:"name=param"` tag, and returns an error describing
+// the violation. The parent is the structure containing the field, for cross-field rules.
+// The value is never a nil pointer, pointers are dereferenced before the rules are applied.
+type ValidationRule func(value reflect.Value, param string, parent reflect.Value) error
+
+var (
+	validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+	timeType      = reflect.TypeOf(time.Time{})
+
+	validationRulesMu sync.RWMutex
+	validationRules   = map[string]ValidationRule{
+		"min":       validateMin,
+		"max":       validateMax,
+		"len":       validateLen,
+		"oneof":     validateOneOf,
+		"regex":     validateRegex,
+		"eqfield":   crossFieldRule("eqfield", "equal", func(c int) bool { return c == 0 }),
+		"nefield":   crossFieldRule("nefield", "not equal", func(c int) bool { return c != 0 }),
+		"gtfield":   crossFieldRule("gtfield", "be greater than", func(c int) bool { return c > 0 }),
+		"gtefield":  crossFieldRule("gtefield", "be greater than or equal to", func(c int) bool { return c >= 0 }),
+		"ltfield":   crossFieldRule("ltfield", "be less than", func(c int) bool { return c < 0 }),
+		"ltefield":  crossFieldRule("ltefield", "be less than or equal to", func(c int) bool { return c <= 0 }),
+		"required":  nil,
+		"omitempty": nil,
+	}
+
+	regexCache sync.Map
+)
+
+// RegisterValidation registers a custom rule which can then be used in `validate` tags by name.
+// Built-in rules cannot be replaced.
+func RegisterValidation(name string, rule ValidationRule) error {
+	if name == "" {
+		return errors.New("name must not be empty")
+	}
+	if rule == nil {
+		return errors.New("rule must not be nil")
+	}
+
+	validationRulesMu.Lock()
+	defer validationRulesMu.Unlock()
+	if _, ok := validationRules[name]; ok {
+		return fmt.Errorf("validation rule: %s is already registered", name)
+	}
+	validationRules[name] = rule
+	return nil
+}
+
+// Validate checks the exported fields of obj, including nested structures and the elements of slices, arrays
+// and maps, against their `validate` tags, e.g. `validate:"required,min=1,max=10"`, and returns all violations
+// as FieldErrors, with paths such as "Items[0].Name" or "Labels[env]".
+//
+// The built-in rules are required, omitempty (skips the other rules if the value is zero), min, max and len
+// (compare numbers, or the length of strings, slices, arrays and maps), oneof=a b, regex=pattern (takes the rest
+// of the tag, so it must be the last rule), and eqfield, nefield, gtfield, gtefield, ltfield, ltefield, which
+// compare with another field of the same structure, e.g. `validate:"gtfield=Start"`. Rules other than required
+// are not applied to nil pointers. Custom rules can be added by RegisterValidation.
+//
+// The Validate method of nested values implementing Validator is called as well, but not the one of obj itself,
+// so that it can call this function.
+// The obj can either be a structure or pointer to structure.
+func Validate(obj interface{}) error {
+	if obj == nil {
+		return errors.New("obj must not be nil")
+	}
+	val := ValuePenetrateElem(obj)
+	if val.Kind() != reflect.Struct {
+		return errors.New("obj must be struct")
+	}
+
+	v := &validation{visited: make(map[visitKey]bool)}
+	if ptr := reflect.ValueOf(obj); ptr.Kind() == reflect.Ptr {
+		v.visited[visitKey{ptr: ptr.Pointer(), typ: ptr.Type()}] = true
+	}
+	v.validateStruct("", val)
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+// validation holds the state of a Validate call. Visited pointers are tracked to stop on cycles.
+type validation struct {
+	errs    FieldErrors
+	visited map[visitKey]bool
+}
+
+func (v *validation) addError(path string, err error) {
+	v.errs = append(v.errs, &FieldError{Path: path, Err: err})
+}
+
+func (v *validation) validateStruct(path string, val reflect.Value) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+		fv := val.Field(i)
+		if tag != "" {
+			v.applyRules(fieldPath, tag, fv, val)
+		}
+		v.validateNested(fieldPath, fv)
+	}
+}
+
+func (v *validation) validateNested(path string, val reflect.Value) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return
+		}
+		if val.Kind() == reflect.Ptr {
+			key := visitKey{ptr: val.Pointer(), typ: val.Type()}
+			if v.visited[key] {
+				return
+			}
+			v.visited[key] = true
+		}
+		val = val.Elem()
+	}
+
+	if validator, ok := asValidator(val); ok {
+		if err := validator.Validate(); err != nil {
+			v.addError(path, err)
+		}
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		v.validateStruct(path, val)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			v.validateNested(fmt.Sprintf("%s[%d]", path, i), val.Index(i))
+		}
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			v.validateNested(fmt.Sprintf("%s[%v]", path, iter.Key()), iter.Value())
+		}
+	}
+}
+
+// asValidator returns the Validator implemented by val or by its address.
+func asValidator(val reflect.Value) (Validator, bool) {
+	if val.CanAddr() && val.Addr().Type().Implements(validatorType) {
+		return val.Addr().Interface().(Validator), true
+	}
+	if val.Type().Implements(validatorType) {
+		return val.Interface().(Validator), true
+	}
+	return nil, false
+}
+
+func (v *validation) applyRules(path, tag string, val, parent reflect.Value) {
+	isZero, isNil := val.IsZero(), false
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			isNil = true
+			break
+		}
+		val = val.Elem()
+	}
+
+	for tag != "" {
+		var rule string
+		rule, tag = nextRule(tag)
+		name, param := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			name, param = rule[:i], rule[i+1:]
+		}
+
+		switch name {
+		case "required":
+			if isZero {
+				v.addError(path, errors.New("is required"))
+				return
+			}
+			continue
+		case "omitempty":
+			if isZero {
+				return
+			}
+			continue
+		}
+
+		validationRulesMu.RLock()
+		fn, ok := validationRules[name]
+		validationRulesMu.RUnlock()
+		if !ok {
+			v.addError(path, fmt.Errorf("unknown validation rule: %s", name))
+			continue
+		}
+		if isNil {
+			continue
+		}
+		if err := fn(val, param, parent); err != nil {
+			v.addError(path, err)
+		}
+	}
+}
+
+// nextRule returns the first rule of the tag and the remaining tag. A regex rule takes the rest of the tag.
+func nextRule(tag string) (string, string) {
+	if strings.HasPrefix(tag, "regex=") {
+		return tag, ""
+	}
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+func validateMin(val reflect.Value, param string, _ reflect.Value) error {
+	return validateBound(val, "min", param, "at least", func(c int) bool { return c >= 0 })
+}
+
+func validateMax(val reflect.Value, param string, _ reflect.Value) error {
+	return validateBound(val, "max", param, "at most", func(c int) bool { return c <= 0 })
+}
+
+func validateLen(val reflect.Value, param string, _ reflect.Value) error {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return fmt.Errorf("invalid param for len: %s", param)
+	}
+	l, ok := valueLen(val)
+	if !ok {
+		return fmt.Errorf("len is not supported for %s", val.Type())
+	}
+	if l != n {
+		return fmt.Errorf("length must be %d", n)
+	}
+	return nil
+}
+
+// validateBound compares the number or the length of val with param.
+func validateBound(val reflect.Value, rule, param, desc string, ok func(int) bool) error {
+	if l, isLen := valueLen(val); isLen {
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return fmt.Errorf("invalid param for %s: %s", rule, param)
+		}
+		if !ok(compareInts(int64(l), int64(n))) {
+			return fmt.Errorf("length must be %s %d", desc, n)
+		}
+		return nil
+	}
+
+	bound, err := convertValue(reflect.ValueOf(param), val.Type())
+	if err != nil || !isNumberKind(val.Kind()) {
+		return fmt.Errorf("invalid param for %s: %s", rule, param)
+	}
+	c, _ := compareValues(val, bound)
+	if !ok(c) {
+		return fmt.Errorf("must be %s %s", desc, param)
+	}
+	return nil
+}
+
+func validateOneOf(val reflect.Value, param string, _ reflect.Value) error {
+	s := fmt.Sprint(val.Interface())
+	for _, option := range strings.Fields(param) {
+		if s == option {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of [%s]", param)
+}
+
+func validateRegex(val reflect.Value, param string, _ reflect.Value) error {
+	if val.Kind() != reflect.String {
+		return fmt.Errorf("regex is not supported for %s", val.Type())
+	}
+	var re *regexp.Regexp
+	if cached, ok := regexCache.Load(param); ok {
+		re = cached.(*regexp.Regexp)
+	} else {
+		var err error
+		re, err = regexp.Compile(param)
+		if err != nil {
+			return fmt.Errorf("invalid param for regex: %w", err)
+		}
+		regexCache.Store(param, re)
+	}
+	if !re.MatchString(val.String()) {
+		return fmt.Errorf("must match %s", param)
+	}
+	return nil
+}
+
+func crossFieldRule(rule, desc string, ok func(int) bool) ValidationRule {
+	return func(val reflect.Value, param string, parent reflect.Value) error {
+		field, found := parent.Type().FieldByName(param)
+		if !found {
+			return fmt.Errorf("field: %s is invalid", param)
+		}
+		other, err := parent.FieldByIndexErr(field.Index)
+		if err != nil {
+			// promoted through a nil embedded pointer, like a nil pointer field
+			return nil
+		}
+		for other.Kind() == reflect.Ptr {
+			if other.IsNil() {
+				return nil
+			}
+			other = other.Elem()
+		}
+		c, err := compareValues(val, other)
+		if err != nil {
+			return fmt.Errorf("%s: %w", rule, err)
+		}
+		if !ok(c) {
+			return fmt.Errorf("must %s %s", desc, param)
+		}
+		return nil
+	}
+}
+
+// valueLen returns the length of val if it is a string, in runes, or a slice, an array or a map.
+func valueLen(val reflect.Value) (int, bool) {
+	switch val.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(val.String()), true
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return val.Len(), true
+	default:
+		return 0, false
+	}
+}
+
+// compareValues compares two numbers, strings or time.Time values, and returns -1, 0 or 1.
+func compareValues(a, b reflect.Value) (int, error) {
+	switch {
+	case a.Type() == timeType && b.Type() == timeType:
+		ta, tb := a.Interface().(time.Time), b.Interface().(time.Time)
+		switch {
+		case ta.Before(tb):
+			return -1, nil
+		case ta.After(tb):
+			return 1, nil
+		default:
+			return 0, nil
+		}
+	case isIntKind(a.Kind()) && isIntKind(b.Kind()):
+		return compareInts(a.Int(), b.Int()), nil
+	case isUintKind(a.Kind()) && isUintKind(b.Kind()):
+		return compareUints(a.Uint(), b.Uint()), nil
+	case isNumberKind(a.Kind()) && isNumberKind(b.Kind()):
+		return compareFloats(toFloat(a), toFloat(b)), nil
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return strings.Compare(a.String(), b.String()), nil
+	default:
+		return 0, fmt.Errorf("cannot compare %s with %s", a.Type(), b.Type())
+	}
+}
+
+func compareInts(x, y int64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareUints(x, y uint64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloats(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func toFloat(val reflect.Value) float64 {
+	switch {
+	case isIntKind(val.Kind()):
+		return float64(val.Int())
+	case isUintKind(val.Kind()):
+		return float64(val.Uint())
+	default:
+		return val.Float()
+	}
+}
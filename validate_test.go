@@ -0,0 +1,149 @@
+package xreflect
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type vdItem struct {
+	Name  string `validate:"required,max=5"`
+	Count int    `validate:"min=1,max=10"`
+}
+
+type vdAddress struct {
+	Zip string `validate:"len=5,regex=^[0-9]{2,5}$"`
+}
+
+func (a vdAddress) Validate() error {
+	if strings.HasPrefix(a.Zip, "0") {
+		return errors.New("zip must not start with 0")
+	}
+	return nil
+}
+
+type vdOrder struct {
+	ID       string            `validate:"required"`
+	Status   string            `validate:"oneof=new paid shipped"`
+	Note     string            `validate:"omitempty,min=3"`
+	Priority *int              `validate:"omitempty,min=1"`
+	Owner    *int              `validate:"required"`
+	Items    []vdItem          `validate:"min=1"`
+	Labels   map[string]vdItem `validate:"max=2"`
+	Address  *vdAddress
+	Start    time.Time
+	End      time.Time `validate:"gtfield=Start"`
+	Low      float64
+	High     int    `validate:"gtefield=Low"`
+	Ignored  string `validate:"-"`
+	Parent   *vdOrder
+	secret   string `validate:"required"`
+}
+
+func (o *vdOrder) Validate() error {
+	return errors.New("root Validate must not be called")
+}
+
+func TestValidate(t *testing.T) {
+	assert.EqualError(t, Validate(nil), "obj must not be nil")
+	assert.EqualError(t, Validate(1), "obj must be struct")
+
+	owner := 1
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	order := &vdOrder{
+		ID:     "1",
+		Status: "paid",
+		Owner:  &owner,
+		Items:  []vdItem{{Name: "a", Count: 1}},
+		Start:  start,
+		End:    start.Add(time.Hour),
+		Low:    1.5,
+		High:   2,
+	}
+	order.Parent = order
+	assert.Equal(t, nil, Validate(order))
+	assert.EqualError(t, Validate(*order), "Parent: root Validate must not be called")
+
+	priority := 0
+	order = &vdOrder{
+		Status:   "lost",
+		Note:     "ab",
+		Priority: &priority,
+		Items:    []vdItem{{Name: "a", Count: 1}, {Name: "toolong", Count: 11}},
+		Labels:   map[string]vdItem{"env": {Count: 1}},
+		Address:  &vdAddress{Zip: "0123"},
+		Start:    start,
+		End:      start,
+		Low:      1.5,
+		High:     1,
+	}
+	err := Validate(order)
+	var errs FieldErrors
+	assert.Equal(t, true, errors.As(err, &errs))
+	assert.Equal(t, []string{
+		"ID: is required",
+		"Status: must be one of [new paid shipped]",
+		"Note: length must be at least 3",
+		"Priority: must be at least 1",
+		"Owner: is required",
+		"Items[1].Name: length must be at most 5",
+		"Items[1].Count: must be at most 10",
+		"Labels[env].Name: is required",
+		"Address: zip must not start with 0",
+		"Address.Zip: length must be 5",
+		"End: must be greater than Start",
+		"High: must be greater than or equal to Low",
+	}, strings.Split(err.Error(), "; "))
+
+	order.Items = nil
+	order.Address.Zip = "1234a"
+	order.Labels = map[string]vdItem{"a": {Name: "a", Count: 1}, "b": {Name: "b", Count: 1}, "c": {Name: "c", Count: 1}}
+	err = Validate(order)
+	assert.Contains(t, err.Error(), "Items: length must be at least 1")
+	assert.Contains(t, err.Error(), "Labels: length must be at most 2")
+	assert.Contains(t, err.Error(), "Address.Zip: must match ^[0-9]{2,5}$")
+}
+
+func TestValidateRules(t *testing.T) {
+	assert.EqualError(t, RegisterValidation("", nil), "name must not be empty")
+	assert.EqualError(t, RegisterValidation("even", nil), "rule must not be nil")
+	assert.EqualError(t, RegisterValidation("min", func(reflect.Value, string, reflect.Value) error { return nil }),
+		"validation rule: min is already registered")
+
+	even := func(value reflect.Value, param string, _ reflect.Value) error {
+		if value.Int()%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	}
+	assert.Equal(t, nil, RegisterValidation("vd_even", even))
+
+	type custom struct {
+		N     int    `validate:"vd_even"`
+		M     *int   `validate:"vd_even"`
+		Bad   int    `validate:"min=x"`
+		Other string `validate:"unknown"`
+		Cmp   string `validate:"eqfield=N"`
+		Ref   string `validate:"ltfield=Missing"`
+	}
+	err := Validate(&custom{N: 1, Cmp: "a"})
+	assert.EqualError(t, err, "N: must be even; Bad: invalid param for min: x; Other: unknown validation rule: unknown; "+
+		"Cmp: eqfield: cannot compare string with int; Ref: field: Missing is invalid")
+}
+
+type VdBase struct {
+	Start int
+}
+
+func TestValidateEmbeddedField(t *testing.T) {
+	type ranged struct {
+		*VdBase
+		End int `validate:"gtfield=Start"`
+	}
+	assert.Equal(t, nil, Validate(&ranged{End: 1}))
+	assert.EqualError(t, Validate(&ranged{VdBase: &VdBase{Start: 2}, End: 1}), "End: must be greater than Start")
+}
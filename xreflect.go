@@ -9,6 +9,8 @@ package xreflect
 import (
 	"fmt"
 	"reflect"
+	"strings"
+	"unsafe"
 )
 
 // NewInstance returns a new instance of the same type as the input value.
@@ -111,13 +113,17 @@ func GetPkgPath(obj interface{}) string {
 }
 
 // Implements returns whether obj implements the given interface in.
+// The in must be a pointer to an interface, e.g. (*io.Reader)(nil), otherwise false is returned.
 func Implements(obj interface{}, in interface{}) bool {
 	objType := reflect.TypeOf(obj)
 	if objType == nil {
 		return false
 	}
 
-	interfaceType := reflect.TypeOf(in).Elem()
+	interfaceType, err := interfaceTypeOf(in)
+	if err != nil {
+		return false
+	}
 	return objType.Implements(interfaceType)
 }
 
@@ -132,6 +138,15 @@ func checkField(field reflect.Value, name string) error {
 	return nil
 }
 
+// accessibleValue returns val read through unsafe if it was obtained via an unexported field and is addressable,
+// so that its Interface method can be called. Otherwise val is returned as is.
+func accessibleValue(val reflect.Value) reflect.Value {
+	if val.CanInterface() || !val.CanAddr() {
+		return val
+	}
+	return reflect.NewAt(val.Type(), unsafe.Pointer(val.UnsafeAddr())).Elem()
+}
+
 func isSupportedKind(k reflect.Kind, kinds []reflect.Kind) bool {
 	for _, v := range kinds {
 		if k == v {
@@ -151,3 +166,35 @@ func isSupportedType(obj interface{}, types []reflect.Kind) bool {
 
 	return false
 }
+
+// methodTypeString returns the signature of a func type such as "func(int, ...string) (bool, error)".
+// If skipReceiver is true, the first parameter is treated as the method receiver and omitted.
+func methodTypeString(typ reflect.Type, skipReceiver bool) string {
+	start := 0
+	if skipReceiver {
+		start = 1
+	}
+
+	var in []string
+	for i := start; i < typ.NumIn(); i++ {
+		if typ.IsVariadic() && i == typ.NumIn()-1 {
+			in = append(in, "..."+typ.In(i).Elem().String())
+			continue
+		}
+		in = append(in, typ.In(i).String())
+	}
+	var out []string
+	for i := 0; i < typ.NumOut(); i++ {
+		out = append(out, typ.Out(i).String())
+	}
+
+	s := "func(" + strings.Join(in, ", ") + ")"
+	switch len(out) {
+	case 0:
+		return s
+	case 1:
+		return s + " " + out[0]
+	default:
+		return s + " (" + strings.Join(out, ", ") + ")"
+	}
+}